@@ -0,0 +1,177 @@
+package simplelog
+
+import (
+	"fmt"
+	"time"
+)
+
+// asyncQueue is the queue of entries waiting for the background writer
+type asyncQueue struct {
+	entries chan asyncItem
+	done    chan struct{}
+}
+
+// asyncItem is a queued entry, or a request from Flush to be told when all
+// entries queued before it have been written
+type asyncItem struct {
+	entry   Entry
+	flushed chan struct{}
+}
+
+// AsyncStats describes the async queue, see SetAsync
+type AsyncStats struct {
+	// Queued is the number of entries waiting to be written
+	Queued int
+	// Capacity is the queue depth; 0 means async mode is off
+	Capacity int
+	// Blocked counts the entries whose caller had to wait for room in a full
+	// queue. A growing count means the outputs can't keep up
+	Blocked uint64
+	// Dropped counts the entries discarded because the queue was full, see
+	// SetAsyncOverflow
+	Dropped uint64
+}
+
+// AsyncOverflow controls what happens to an entry logged while the async
+// queue is full
+type AsyncOverflow int
+
+const (
+	// AsyncBlock waits for room in the queue
+	AsyncBlock AsyncOverflow = iota
+	// AsyncDrop discards the entry and counts it, so logging never waits
+	AsyncDrop
+)
+
+// SetAsyncOverflow sets what happens to entries logged while the async queue
+// is full. With AsyncDrop and a summaryEvery above 0, a WARN entry reporting
+// how many entries were dropped is logged at that interval whenever any were
+func (l *Logger) SetAsyncOverflow(policy AsyncOverflow, summaryEvery time.Duration) {
+	l.asyncMu.Lock()
+	defer l.asyncMu.Unlock()
+
+	l.overflow = policy
+	l.stopDropSummary()
+	if policy == AsyncDrop && summaryEvery > 0 {
+		l.summaryStop = make(chan struct{})
+		go l.summarizeDrops(summaryEvery, l.asyncDropped.Load(), l.summaryStop)
+	}
+}
+
+// stopDropSummary stops the drop summary goroutine. l.asyncMu must be held
+func (l *Logger) stopDropSummary() {
+	if l.summaryStop != nil {
+		close(l.summaryStop)
+		l.summaryStop = nil
+	}
+}
+
+// summarizeDrops logs the number of entries dropped beyond reported every
+// interval until stop is closed. The summary is written directly rather than queued, so that a
+// queue that is still full doesn't drop it too; drops are only counted as
+// reported once it has been written
+func (l *Logger) summarizeDrops(interval time.Duration, reported uint64, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if n := l.asyncDropped.Load(); n > reported {
+				entry := l.newEntry(-1, WARN, fmt.Sprintf("Dropped %d log entries, async queue full", n-reported), nil)
+				if _, ok := l.deliver(entry); ok {
+					reported = n
+				}
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// SetAsync makes the logger queue entries and write them from a background
+// goroutine, so logging doesn't wait on the outputs unless queueSize entries
+// are already waiting. Hooks then run on the background goroutine, and must
+// not log through the same logger, which would wait on itself once the queue
+// is full. A queueSize of 0 turns async mode off, first writing any queued
+// entries
+func (l *Logger) SetAsync(queueSize int) {
+	var q *asyncQueue
+	if queueSize > 0 {
+		q = &asyncQueue{entries: make(chan asyncItem, queueSize), done: make(chan struct{})}
+		go func() {
+			defer close(q.done)
+			for item := range q.entries {
+				if item.flushed != nil {
+					close(item.flushed)
+					continue
+				}
+				l.deliver(item.entry)
+			}
+		}()
+	}
+
+	l.asyncMu.Lock()
+	old := l.async
+	l.async = q
+	if old != nil {
+		close(old.entries)
+	}
+	l.asyncMu.Unlock()
+
+	if old != nil {
+		<-old.done
+	}
+}
+
+// AsyncStats returns the state of the async queue
+func (l *Logger) AsyncStats() AsyncStats {
+	l.asyncMu.RLock()
+	defer l.asyncMu.RUnlock()
+
+	stats := AsyncStats{Blocked: l.asyncBlocked.Load(), Dropped: l.asyncDropped.Load()}
+	if l.async != nil {
+		stats.Queued = len(l.async.entries)
+		stats.Capacity = cap(l.async.entries)
+	}
+	return stats
+}
+
+// enqueue hands entry to the background writer, timestamping it now. It
+// reports whether async mode is on, and if so whether the entry was queued
+// rather than dropped
+func (l *Logger) enqueue(entry *Entry) (async, queued bool) {
+	l.asyncMu.RLock()
+	defer l.asyncMu.RUnlock()
+
+	if l.async == nil {
+		return false, false
+	}
+	entry.Time = time.Now()
+	select {
+	case l.async.entries <- asyncItem{entry: *entry}:
+	default:
+		if l.overflow == AsyncDrop {
+			l.asyncDropped.Add(1)
+			return true, false
+		}
+		l.asyncBlocked.Add(1)
+		l.async.entries <- asyncItem{entry: *entry}
+	}
+	return true, true
+}
+
+// drainAsync waits until the entries queued so far have been written
+func (l *Logger) drainAsync() {
+	l.asyncMu.RLock()
+	q := l.async
+	flushed := make(chan struct{})
+	if q != nil {
+		q.entries <- asyncItem{flushed: flushed}
+	}
+	l.asyncMu.RUnlock()
+
+	if q != nil {
+		<-flushed
+	}
+}
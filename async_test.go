@@ -0,0 +1,55 @@
+package simplelog
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedWriter blocks writes until open is closed
+type gatedWriter struct {
+	open chan struct{}
+	mu   sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	<-w.open
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gatedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestDropSummaryWrittenWhileQueueFull(t *testing.T) {
+	w := &gatedWriter{open: make(chan struct{})}
+	l := NewWriter(INFO, w)
+	l.SetAsync(1)
+	defer l.SetAsync(0)
+	l.SetAsyncOverflow(AsyncDrop, 10*time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		l.Info("entry")
+	}
+	if l.AsyncStats().Dropped == 0 {
+		t.Fatal("no entries were dropped")
+	}
+	// Let the summary come due while the queue is still full
+	time.Sleep(30 * time.Millisecond)
+	close(w.open)
+
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(w.String(), "async queue full") {
+		if time.Now().After(deadline) {
+			t.Fatalf("no drop summary in %q", w.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
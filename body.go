@@ -0,0 +1,101 @@
+package simplelog
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultBodyTypes are the content types whose bodies GinBodies logs unless
+// given others
+var DefaultBodyTypes = []string{"application/json", "application/xml", "application/x-www-form-urlencoded", "text/"}
+
+type bodyConfig struct {
+	limit int
+	types []string
+}
+
+// GinBodies adds request_body and response_body fields holding up to limit
+// bytes of each body, marked if truncated. Only bodies whose content type
+// starts with one of types are captured, DefaultBodyTypes if none are given,
+// so that uploads and binary downloads stay out of the log
+func GinBodies(limit int, types ...string) GinOption {
+	if len(types) == 0 {
+		types = DefaultBodyTypes
+	}
+	return func(cfg *ginConfig) {
+		cfg.bodies = &bodyConfig{limit: limit, types: types}
+	}
+}
+
+// logged reports whether bodies of contentType are captured
+func (cfg *bodyConfig) logged(contentType string) bool {
+	if contentType == "" {
+		return false
+	}
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mt
+	}
+	for _, t := range cfg.types {
+		if strings.HasPrefix(contentType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// captureRequest reads up to the limit of c's request body, leaving the body
+// intact for the handlers. It returns "" if the body isn't captured
+func (cfg *bodyConfig) captureRequest(c *gin.Context) string {
+	body := c.Request.Body
+	if body == nil || body == http.NoBody || !cfg.logged(c.ContentType()) {
+		return ""
+	}
+	head, _ := io.ReadAll(io.LimitReader(body, int64(cfg.limit)+1))
+	c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(head), body), body}
+	return cfg.text(head)
+}
+
+// text returns a captured body, truncated to the limit
+func (cfg *bodyConfig) text(b []byte) string {
+	if len(b) > cfg.limit {
+		return string(b[:cfg.limit]) + truncatedSuffix
+	}
+	return string(b)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyWriter keeps the first bytes of a response written through it
+type bodyWriter struct {
+	gin.ResponseWriter
+	buf   bytes.Buffer
+	limit int
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	w.keep(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// keep adds b to the captured body, up to one byte past the limit
+func (w *bodyWriter) keep(b []byte) {
+	if room := w.limit + 1 - w.buf.Len(); room > 0 {
+		if len(b) > room {
+			b = b[:room]
+		}
+		w.buf.Write(b)
+	}
+}
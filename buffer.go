@@ -0,0 +1,98 @@
+package simplelog
+
+import (
+	"bufio"
+	"time"
+)
+
+const fileBufferSize = 64 * 1024
+
+// SetBuffering buffers writes to the log file, flushing once maxEntries
+// entries have accumulated or interval has passed since the last flush,
+// whichever comes first. A zero threshold disables that trigger; disabling
+// both turns buffering off. Call Flush before exiting so buffered entries
+// aren't lost
+func (l *Logger) SetBuffering(maxEntries int, interval time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.flushStop != nil {
+		close(l.flushStop)
+		l.flushStop = nil
+	}
+	l.flushLocked()
+
+	l.bufMax = maxEntries
+	l.bufInterval = interval
+	if l.file == nil || (maxEntries <= 0 && interval <= 0) {
+		l.buf = nil
+		return
+	}
+
+	l.buf = bufio.NewWriterSize(l.file, fileBufferSize)
+	l.lastFlush = time.Now()
+	if interval > 0 {
+		l.flushStop = make(chan struct{})
+		go l.flushEvery(interval, l.flushStop)
+	}
+}
+
+// Flush writes any queued and buffered entries to the outputs and the log file
+func (l *Logger) Flush() error {
+	l.drainAsync()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.endDuplicateRunLocked()
+	return l.flushLocked()
+}
+
+// flushEvery flushes the buffer whenever interval has passed without a flush,
+// so that a trickle of entries isn't held indefinitely
+func (l *Logger) flushEvery(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.mu.Lock()
+			if l.bufEntries > 0 && time.Since(l.lastFlush) >= interval {
+				l.flushLocked()
+			}
+			l.mu.Unlock()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// writeFile writes a formatted entry to the log file, through the buffer if
+// buffering is enabled. l.mu must be held
+func (l *Logger) writeFile(b []byte) error {
+	if l.buf == nil {
+		_, err := l.file.Write(b)
+		return err
+	}
+
+	if _, err := l.buf.Write(b); err != nil {
+		return err
+	}
+	l.bufEntries++
+	if (l.bufMax > 0 && l.bufEntries >= l.bufMax) ||
+		(l.bufInterval > 0 && time.Since(l.lastFlush) >= l.bufInterval) {
+		return l.flushLocked()
+	}
+	return nil
+}
+
+// flushLocked flushes the file buffer. l.mu must be held
+func (l *Logger) flushLocked() error {
+	if l.buf == nil {
+		return nil
+	}
+	l.bufEntries = 0
+	l.lastFlush = time.Now()
+	return l.buf.Flush()
+}
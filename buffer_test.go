@@ -0,0 +1,79 @@
+package simplelog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newFileLogger(t *testing.T) (*Logger, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, err := New(WithFile(path), WithStdout(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l, path
+}
+
+func fileLines(t *testing.T, path string) int {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Count(string(b), "\n")
+}
+
+func TestBufferingFlushesAtCount(t *testing.T) {
+	l, path := newFileLogger(t)
+	l.SetBuffering(3, 0)
+
+	l.Info("one")
+	l.Info("two")
+	if n := fileLines(t, path); n != 0 {
+		t.Fatalf("%d lines written before the count was reached", n)
+	}
+	l.Info("three")
+	if n := fileLines(t, path); n != 3 {
+		t.Fatalf("got %d lines after 3 entries, want 3", n)
+	}
+}
+
+func TestBufferingFlushesOnTimer(t *testing.T) {
+	l, path := newFileLogger(t)
+	l.SetBuffering(100, 20*time.Millisecond)
+
+	l.Info("one")
+	l.Info("two")
+	if n := fileLines(t, path); n != 0 {
+		t.Fatalf("%d lines written before the interval passed", n)
+	}
+	deadline := time.Now().Add(time.Second)
+	for fileLines(t, path) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d lines, want 2 flushed by the timer", fileLines(t, path))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBufferedBytesCountTowardsRotation(t *testing.T) {
+	l, path := newFileLogger(t)
+	l.SetBuffering(100, 0)
+	l.SetMaxFileSize(200)
+
+	for i := 0; i < 20; i++ {
+		l.Info("an entry long enough to fill the file quickly")
+	}
+	rotated, err := filepath.Glob(filepath.Join(filepath.Dir(path), "app*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rotated) < 2 {
+		t.Errorf("no rotation while the buffered entries exceeded the size limit: %v", rotated)
+	}
+}
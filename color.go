@@ -0,0 +1,108 @@
+package simplelog
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// ColorMode controls whether console output uses ANSI colors
+type ColorMode int
+
+const (
+	// ColorAuto colors console output based on the NO_COLOR, FORCE_COLOR and
+	// CLICOLOR_FORCE environment variables, falling back to TTY detection
+	ColorAuto ColorMode = iota
+	// ColorAlways colors console output regardless of environment and TTY
+	ColorAlways
+	// ColorNever disables colored console output
+	ColorNever
+)
+
+const colorReset = "\033[0m"
+
+var levelColors = map[LogLevel]string{
+	TRACE: "\033[2;90m",
+	DEBUG: "\033[90m",
+	INFO:  "\033[32m",
+	WARN:  "\033[33m",
+	ERROR: "\033[31m",
+	PANIC: "\033[35m",
+	FATAL: "\033[1;35m",
+}
+
+// resolveColor decides whether to color output written to w.
+// Precedence is: explicit mode > NO_COLOR > FORCE_COLOR/CLICOLOR_FORCE > TTY detection
+func resolveColor(mode ColorMode, w interface{}) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if envForcesColor("FORCE_COLOR") || envForcesColor("CLICOLOR_FORCE") {
+		return true
+	}
+
+	return isTerminal(w)
+}
+
+// isTerminal reports whether w is a terminal. It is a variable so tests can
+// stand in for a TTY
+var isTerminal = func(w interface{}) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+func envForcesColor(name string) bool {
+	v := os.Getenv(name)
+	return v != "" && v != "0" && v != "false"
+}
+
+func colorize(level LogLevel, s string) string {
+	c, ok := levelColors[level]
+	if !ok {
+		// Custom levels take the color of the built-in level below them
+		for _, b := range builtinLevels {
+			if b < level {
+				c, ok = levelColors[b]
+			}
+		}
+	}
+	if !ok {
+		return s
+	}
+	return c + s + colorReset
+}
+
+func defaultLevelIcons() map[LogLevel]string {
+	return map[LogLevel]string{
+		TRACE: "🔍",
+		DEBUG: "🐛",
+		INFO:  "ℹ️",
+		WARN:  "⚠️",
+		ERROR: "❌",
+		PANIC: "🔥",
+		FATAL: "💀",
+	}
+}
+
+// ansiEscape matches CSI sequences (colors, cursor movement) and OSC sequences (titles, links)
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)`)
+
+// stripANSI removes ANSI escape sequences from s
+func stripANSI(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	return ansiEscape.ReplaceAllString(s, "")
+}
@@ -0,0 +1,67 @@
+package simplelog
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestResolveColor(t *testing.T) {
+	tests := []struct {
+		name string
+		mode ColorMode
+		env  map[string]string
+		tty  bool
+		want bool
+	}{
+		{"forced", ColorAlways, nil, false, true},
+		{"forced over NO_COLOR", ColorAlways, map[string]string{"NO_COLOR": "1"}, false, true},
+		{"never", ColorNever, nil, true, false},
+		{"never over FORCE_COLOR", ColorNever, map[string]string{"FORCE_COLOR": "1"}, true, false},
+		{"auto with TTY", ColorAuto, nil, true, true},
+		{"auto redirected", ColorAuto, nil, false, false},
+		{"NO_COLOR with TTY", ColorAuto, map[string]string{"NO_COLOR": "1"}, true, false},
+		{"NO_COLOR over FORCE_COLOR", ColorAuto, map[string]string{"NO_COLOR": "1", "FORCE_COLOR": "1"}, true, false},
+		{"FORCE_COLOR redirected", ColorAuto, map[string]string{"FORCE_COLOR": "1"}, false, true},
+		{"CLICOLOR_FORCE redirected", ColorAuto, map[string]string{"CLICOLOR_FORCE": "1"}, false, true},
+		{"FORCE_COLOR=0", ColorAuto, map[string]string{"FORCE_COLOR": "0"}, false, false},
+	}
+
+	tty := os.Stdout
+	defer func(f func(interface{}) bool) { isTerminal = f }(isTerminal)
+	isTerminal = func(w interface{}) bool { return w == tty }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR_FORCE"} {
+				t.Setenv(name, tt.env[name])
+			}
+			var w interface{} = &bytes.Buffer{}
+			if tt.tty {
+				w = tty
+			}
+			if got := resolveColor(tt.mode, w); got != tt.want {
+				t.Errorf("resolveColor = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelIconsOnlyOnTerminal(t *testing.T) {
+	defer func(f func(interface{}) bool) { isTerminal = f }(isTerminal)
+	t.Setenv("NO_COLOR", "1")
+
+	for _, tty := range []bool{false, true} {
+		isTerminal = func(interface{}) bool { return tty }
+		var out bytes.Buffer
+		l := NewWriter(INFO, &out)
+		l.SetLevelIcons(true)
+		l.SetLevelIcon(WARN, "!!")
+		l.Warn("disk almost full")
+
+		if got := strings.HasPrefix(out.String(), "!! "); got != tty {
+			t.Errorf("terminal %v: got %q", tty, out.String())
+		}
+	}
+}
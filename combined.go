@@ -0,0 +1,64 @@
+package simplelog
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CombinedLogFormat renders req in the Apache/NGINX combined log format, for
+// GinFormat, HTTPFormat and the WithFormat options of slecho and slfiber:
+//
+//	203.0.113.9 - alice [02/Jan/2024:10:00:00 +0000] "GET /index.html HTTP/1.1" 200 1024 "https://example.com/" "Mozilla/5.0"
+//
+// Analyzers such as GoAccess expect nothing else on the line, so log with it
+// to a logger of its own using PlainFormatter
+func CombinedLogFormat(req Request) string {
+	var b strings.Builder
+	b.WriteString(orDash(req.ClientIP))
+	b.WriteString(" - ")
+	b.WriteString(orDash(req.User))
+	b.WriteString(" [")
+	b.WriteString(req.Time.Format("02/Jan/2006:15:04:05 -0700"))
+	b.WriteString(`] "`)
+	b.WriteString(combinedEscape(req.Method + " " + req.Path + " " + req.Proto))
+	b.WriteString(`" `)
+	b.WriteString(strconv.Itoa(req.Status))
+	b.WriteByte(' ')
+	if req.Size > 0 {
+		b.WriteString(strconv.Itoa(req.Size))
+	} else {
+		b.WriteByte('-')
+	}
+	b.WriteString(` "`)
+	b.WriteString(combinedEscape(orDash(req.Referer)))
+	b.WriteString(`" "`)
+	b.WriteString(combinedEscape(orDash(req.UserAgent)))
+	b.WriteByte('"')
+	return b.String()
+}
+
+// PlainFormatter writes just the message of each entry, followed by its
+// stack, if any. Fields are left out
+type PlainFormatter struct{}
+
+func (PlainFormatter) Format(entry Entry) ([]byte, error) {
+	line := entry.Message
+	if entry.Stack != "" {
+		line += "\n" + entry.Stack
+	}
+	return []byte(line + "\n"), nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+var combinedReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// combinedEscape escapes quotes and control characters the way NGINX does
+func combinedEscape(s string) string {
+	return combinedReplacer.Replace(s)
+}
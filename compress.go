@@ -0,0 +1,88 @@
+package simplelog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+)
+
+// Compression selects what happens to a log file after it is rotated
+type Compression int
+
+const (
+	// CompressNone leaves rotated files as they are
+	CompressNone Compression = iota
+	// CompressGzip gzips rotated files before the write that triggered the
+	// rotation continues. The logger's lock is held meanwhile, so every
+	// goroutine that logs waits for the whole file to be compressed
+	CompressGzip
+	// CompressGzipAsync gzips rotated files in a background goroutine; Close
+	// waits for it to finish. This is the default
+	CompressGzipAsync
+)
+
+// SetCompression sets how rotated log files are compressed, see Compression.
+// With CompressGzip, logging stalls while each rotated file is compressed
+func (l *Logger) SetCompression(mode Compression) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.compress = mode
+}
+
+// archive compresses the just rotated file name according to the compression
+// mode, then applies the retention limits. l.mu must be held
+func (l *Logger) archive(name string) {
+	patterns, file, keep, age := l.rotatedPatterns(), l.fileName, l.maxBackups, l.maxAge
+	switch l.compress {
+	case CompressGzip:
+		gzipFile(name)
+	case CompressGzipAsync:
+		l.compressWG.Add(1)
+		go func() {
+			defer l.compressWG.Done()
+			gzipFile(name)
+			pruneBackups(patterns, file, keep, age)
+		}()
+		return
+	}
+	pruneBackups(patterns, file, keep, age)
+}
+
+// gzipFile replaces name with name.gz. On failure the original file is kept
+func gzipFile(name string) error {
+	in, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmp := name + ".gz.tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	_, err = io.Copy(zw, in)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		// Keep the rotation time so retention by age and order still apply
+		os.Chtimes(tmp, fi.ModTime(), fi.ModTime())
+		err = os.Rename(tmp, name+".gz")
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	in.Close()
+	return os.Remove(name)
+}
@@ -0,0 +1,329 @@
+package simplelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is a declarative logger configuration, read from a YAML or JSON
+// file by NewFromConfig, e.g.
+//
+//	level: debug
+//	format: json
+//	file: /var/log/app.log
+//	rotation:
+//	  max_size: 50MB
+//	  period: daily
+//	  max_backups: 14
+//	  max_age: 30d
+type Config struct {
+	// Level is the minimum level logged; the logger's own level is kept if
+	// unset, INFO for a new logger
+	Level *LogLevel `json:"level" yaml:"level"`
+	// Format is text, json, logfmt, gelf, gcp, datadog or rfc5424; the
+	// logger's own format is kept if unset
+	Format *Format `json:"format" yaml:"format"`
+	// TimeFormat is the time layout of the text format
+	TimeFormat string `json:"time_format" yaml:"time_format"`
+	// Caller is short, full or none, see SetCallerMode; the logger's own
+	// setting is kept if unset
+	Caller *CallerMode `json:"caller" yaml:"caller"`
+	// Console is stdout (the default), stderr or none
+	Console string `json:"console" yaml:"console"`
+	// File is the log file; none if empty
+	File string `json:"file" yaml:"file"`
+	// Rotation configures rotation of File
+	Rotation RotationConfig `json:"rotation" yaml:"rotation"`
+}
+
+// RotationConfig is the rotation part of a Config
+type RotationConfig struct {
+	// MaxSize is the size the file is rotated at, e.g. 10MB
+	MaxSize string `json:"max_size" yaml:"max_size"`
+	// Period is hourly or daily for scheduled rotation, see SetRotationPeriod
+	Period string `json:"period" yaml:"period"`
+	// MaxBackups is the number of rotated files kept, see SetMaxBackups
+	MaxBackups *int `json:"max_backups" yaml:"max_backups"`
+	// MaxAge is how long rotated files are kept, e.g. 720h or 30d
+	MaxAge string `json:"max_age" yaml:"max_age"`
+	// Compress is async (the default), gzip or none, see SetCompression. gzip
+	// stalls logging while each rotated file is compressed
+	Compress string `json:"compress" yaml:"compress"`
+	// NamePattern names rotated files, see SetRotatedNamePattern
+	NamePattern string `json:"name_pattern" yaml:"name_pattern"`
+}
+
+// rotationSettings is a RotationConfig with its values parsed
+type rotationSettings struct {
+	maxSize  int64
+	period   RotationPeriod
+	maxAge   time.Duration
+	compress Compression
+}
+
+// LoadConfig reads a Config from a .yaml, .yml or .json file, checking its
+// values
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&cfg)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&cfg)
+	default:
+		return cfg, fmt.Errorf("simplelog: config %s: unknown file type, want .yaml, .yml or .json", path)
+	}
+	if err == nil {
+		_, err = cfg.check()
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("simplelog: config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// NewFromConfig creates a Logger configured by the file at path, see
+// LoadConfig. opts are applied before the file's settings
+func NewFromConfig(path string, opts ...Option) (*Logger, error) {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Level != nil {
+		opts = append(opts, WithLevel(*cfg.Level))
+	}
+	if cfg.Format != nil {
+		opts = append(opts, WithFormat(*cfg.Format))
+	}
+	if cfg.Caller != nil {
+		opts = append(opts, WithCallerMode(*cfg.Caller))
+	}
+	if cfg.TimeFormat != "" {
+		opts = append(opts, WithTimeFormat(cfg.TimeFormat))
+	}
+	switch cfg.Console {
+	case "stderr":
+		opts = append(opts, WithConsole(os.Stderr))
+	case "none":
+		opts = append(opts, WithStdout(false))
+	}
+	if cfg.File != "" {
+		opts = append(opts, WithFile(cfg.File))
+	}
+	l, err := New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	rs, _ := cfg.check()
+	l.applyRotation(cfg.Rotation, rs)
+	l.configPath = path
+	return l, nil
+}
+
+var errNoConfig = errors.New("simplelog: logger was not created from a config file")
+
+// Reload reads the config file the logger was created from by NewFromConfig
+// again and applies it to the running logger, e.g. to raise the level to WARN
+// during an incident. Settings are applied as NewFromConfig would, except that
+// keys missing from the file leave the current setting in place; a new file
+// name switches to that file. If the config can't be read or is invalid,
+// nothing changes and the error is returned
+func (l *Logger) Reload() error {
+	l.mu.Lock()
+	path := l.configPath
+	l.mu.Unlock()
+	if path == "" {
+		return errNoConfig
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	rs, _ := cfg.check()
+
+	l.mu.Lock()
+	if cfg.File != "" && cfg.File != l.fileName {
+		file, err := os.OpenFile(cfg.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			l.mu.Unlock()
+			return fmt.Errorf("simplelog: opening log file: %w", err)
+		}
+		if l.file != nil {
+			l.flushLocked()
+			l.file.Close()
+		}
+		l.fileName = cfg.File
+		l.file = file
+		l.openedAt = time.Now()
+		if l.buf != nil {
+			l.buf.Reset(file)
+		}
+	}
+	switch cfg.Console {
+	case "stdout":
+		l.console = os.Stdout
+	case "stderr":
+		l.console = os.Stderr
+	case "none":
+		l.console = nil
+	}
+	l.resolveConsole()
+	if cfg.TimeFormat != "" {
+		l.timeFormat = cfg.TimeFormat
+	}
+	l.mu.Unlock()
+
+	if cfg.Level != nil {
+		l.SetLevel(*cfg.Level)
+	}
+	if cfg.Format != nil {
+		l.SetFormat(*cfg.Format)
+	}
+	if cfg.Caller != nil {
+		l.SetCallerMode(*cfg.Caller)
+	}
+	l.applyRotation(cfg.Rotation, rs)
+	return nil
+}
+
+// WatchConfig checks the config file for changes every interval and calls
+// Reload when it changes, logging an error if reloading fails. Calling the
+// returned function stops watching. It returns an error if the logger was
+// not created by NewFromConfig
+func (l *Logger) WatchConfig(interval time.Duration) (stop func(), err error) {
+	l.mu.Lock()
+	path := l.configPath
+	l.mu.Unlock()
+	if path == "" {
+		return nil, errNoConfig
+	}
+
+	last, _ := os.Stat(path)
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				fi, err := os.Stat(path)
+				if err != nil || last != nil && fi.ModTime().Equal(last.ModTime()) && fi.Size() == last.Size() {
+					continue
+				}
+				last = fi
+				if err := l.Reload(); err != nil {
+					l.Error("Could not reload logging config: %v", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }, nil
+}
+
+// check validates the values of c that New doesn't, returning them parsed
+func (c Config) check() (rotationSettings, error) {
+	var rs rotationSettings
+	switch c.Console {
+	case "", "stdout", "stderr", "none":
+	default:
+		return rs, fmt.Errorf("console: want stdout, stderr or none, got %q", c.Console)
+	}
+
+	r := c.Rotation
+	var err error
+	if r.MaxSize != "" {
+		if rs.maxSize, err = parseSize(r.MaxSize); err != nil {
+			return rs, fmt.Errorf("rotation.max_size: %w", err)
+		}
+	}
+	switch r.Period {
+	case "":
+	case "hourly":
+		rs.period = RotateHourly
+	case "daily":
+		rs.period = RotateDaily
+	default:
+		return rs, fmt.Errorf("rotation.period: want hourly or daily, got %q", r.Period)
+	}
+	if r.MaxAge != "" {
+		if rs.maxAge, err = parseAge(r.MaxAge); err != nil {
+			return rs, fmt.Errorf("rotation.max_age: %w", err)
+		}
+	}
+	switch r.Compress {
+	case "gzip":
+		rs.compress = CompressGzip
+	case "", "async":
+		rs.compress = CompressGzipAsync
+	case "none":
+		rs.compress = CompressNone
+	default:
+		return rs, fmt.Errorf("rotation.compress: want async, gzip or none, got %q", r.Compress)
+	}
+	if r.MaxBackups != nil && *r.MaxBackups < 0 {
+		return rs, fmt.Errorf("rotation.max_backups: must not be negative")
+	}
+	return rs, nil
+}
+
+// applyRotation applies the rotation settings of a checked config, leaving
+// those it doesn't set as they are
+func (l *Logger) applyRotation(r RotationConfig, rs rotationSettings) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if rs.maxSize > 0 {
+		l.maxSize = rs.maxSize
+	}
+	if r.Period != "" {
+		l.period = rs.period
+		l.rotateAt = rs.period.next(l.openedAt)
+	}
+	if r.MaxBackups != nil {
+		l.maxBackups = *r.MaxBackups
+	}
+	if r.MaxAge != "" {
+		l.maxAge = rs.maxAge
+	}
+	if r.Compress != "" {
+		l.compress = rs.compress
+	}
+	if r.NamePattern != "" {
+		l.naming = r.NamePattern
+	}
+}
+
+// parseAge parses a duration such as 720h, or a number of days such as 30d
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
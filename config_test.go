@@ -0,0 +1,104 @@
+package simplelog
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "log.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewFromConfigCallerMode(t *testing.T) {
+	path := writeConfig(t, "console: none\n")
+	l, err := NewFromConfig(path, WithCallerMode(CallerNone))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if CallerMode(l.callerMode.Load()) != CallerNone {
+		t.Errorf("without a caller key: got mode %d, want CallerNone", l.callerMode.Load())
+	}
+
+	path = writeConfig(t, "console: none\ncaller: full\n")
+	if l, err = NewFromConfig(path, WithCallerMode(CallerNone)); err != nil {
+		t.Fatal(err)
+	}
+	if CallerMode(l.callerMode.Load()) != CallerFull {
+		t.Errorf("with caller: full: got mode %d, want CallerFull", l.callerMode.Load())
+	}
+}
+
+func TestReloadKeepsCallerMode(t *testing.T) {
+	path := writeConfig(t, "console: none\n")
+	l, err := NewFromConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.SetCallerMode(CallerNone)
+	if err := l.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if CallerMode(l.callerMode.Load()) != CallerNone {
+		t.Errorf("got mode %d after reload, want CallerNone", l.callerMode.Load())
+	}
+}
+
+func TestReloadKeepsUnsetKeys(t *testing.T) {
+	path := writeConfig(t, "console: none\nlevel: warn\n")
+	l, err := NewFromConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.GetLevel() != WARN {
+		t.Fatalf("got level %v, want WARN", l.GetLevel())
+	}
+	custom := FormatterFunc(func(entry Entry) ([]byte, error) { return []byte(entry.Message + "\n"), nil })
+	l.SetFormatter(custom)
+	l.SetLevel(DEBUG)
+	l.SetMaxBackups(3)
+
+	if err := os.WriteFile(path, []byte("console: none\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if l.GetLevel() != DEBUG {
+		t.Errorf("got level %v after reload, want DEBUG", l.GetLevel())
+	}
+	if _, ok := l.formatter.(FormatterFunc); !ok {
+		t.Errorf("custom formatter replaced by %T", l.formatter)
+	}
+	if l.maxBackups != 3 {
+		t.Errorf("got %d backups after reload, want 3", l.maxBackups)
+	}
+
+	if err := os.WriteFile(path, []byte("level: error\nformat: json\nrotation:\n  max_backups: 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if l.GetLevel() != ERROR || l.maxBackups != 0 {
+		t.Errorf("got level %v and %d backups, want ERROR and 0", l.GetLevel(), l.maxBackups)
+	}
+	if _, ok := l.formatter.(*JSONFormatter); !ok {
+		t.Errorf("got formatter %T, want JSON", l.formatter)
+	}
+}
+
+func TestWatchConfigWithoutPath(t *testing.T) {
+	l := NewWriter(INFO, io.Discard)
+	if stop, err := l.WatchConfig(time.Millisecond); err == nil {
+		stop()
+		t.Error("WatchConfig succeeded on a logger without a config file")
+	}
+}
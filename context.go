@@ -0,0 +1,68 @@
+package simplelog
+
+import "context"
+
+// ContextExtractor returns fields to add to entries logged for ctx, e.g. a
+// request ID stored in it by middleware. It returns nil if ctx has none
+type ContextExtractor func(ctx context.Context) []Field
+
+// AddContextExtractor registers fn to add fields to loggers returned by Ctx
+// and FromContext, for l and the loggers sharing its outputs
+func (l *Logger) AddContextExtractor(fn ContextExtractor) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.extractors = append(l.extractors, fn)
+}
+
+// Ctx returns a logger that adds the fields the context extractors find in
+// ctx to every entry it logs. The returned logger shares its outputs and
+// settings with l
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	l.mu.Lock()
+	extractors := l.extractors
+	l.mu.Unlock()
+
+	c := l.clone()
+	if len(extractors) == 0 {
+		return c
+	}
+	for _, extract := range extractors {
+		for _, field := range extract(ctx) {
+			c.fields = withField(c.fields, field.Key, field.Value)
+		}
+	}
+	c.encoded = encodeFields(c.fields)
+	return c
+}
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx carrying l, for FromContext
+func (l *Logger) WithContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx by WithContext, or the
+// default logger if there is none, with the fields its
+// context extractors find in ctx, see Ctx
+func FromContext(ctx context.Context) *Logger {
+	l, ok := ctx.Value(contextKey{}).(*Logger)
+	if !ok {
+		l = Default()
+	}
+	return l.Ctx(ctx)
+}
+
+// ContextValue returns an extractor adding the field key with the value
+// stored in ctx under ctxKey, when there is one, e.g.
+//
+//	l.AddContextExtractor(simplelog.ContextValue(requestIDKey{}, "request_id"))
+func ContextValue(ctxKey interface{}, key string) ContextExtractor {
+	return func(ctx context.Context) []Field {
+		if v := ctx.Value(ctxKey); v != nil {
+			return []Field{{Key: key, Value: v}}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,95 @@
+package simplelog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DatadogFormatter renders entries as JSON using Datadog's reserved
+// attributes, so that the Datadog Agent or intake API sets each log's status,
+// service and source and links it to its trace
+type DatadogFormatter struct {
+	// Service is the service attribute, e.g. from DD_SERVICE
+	Service string
+	// Source is the ddsource attribute; it defaults to "go"
+	Source string
+}
+
+var datadogReservedKeys = map[string]bool{"timestamp": true, "status": true, "message": true, "service": true,
+	"ddsource": true, "logger.name": true, "logger.method_name": true, "dd.trace_id": true, "dd.span_id": true, "error.stack": true}
+
+func (f *DatadogFormatter) Format(entry Entry) ([]byte, error) {
+	source := f.Source
+	if source == "" {
+		source = "go"
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	writeJSONField(&buf, "timestamp", entry.Time.Format(time.RFC3339Nano))
+	buf.WriteByte(',')
+	writeJSONField(&buf, "status", strings.ToLower(gcpSeverity(entry.Level)))
+	buf.WriteByte(',')
+	writeJSONField(&buf, "message", entry.Message)
+	if f.Service != "" {
+		buf.WriteByte(',')
+		writeJSONField(&buf, "service", f.Service)
+	}
+	buf.WriteByte(',')
+	writeJSONField(&buf, "ddsource", source)
+	if entry.Logger != "" {
+		buf.WriteByte(',')
+		writeJSONField(&buf, "logger.name", entry.Logger)
+	}
+	if entry.File != "" {
+		buf.WriteByte(',')
+		writeJSONField(&buf, "caller", fmt.Sprintf("%s:%d", entry.File, entry.Line))
+	}
+	if entry.Function != "" {
+		buf.WriteByte(',')
+		writeJSONField(&buf, "logger.method_name", entry.Function)
+	}
+	for _, field := range entry.Fields {
+		key := field.Key
+		s, isString := field.Value.(string)
+		switch {
+		case key == "trace_id" && isString:
+			key, field.Value = "dd.trace_id", datadogID(s)
+		case key == "span_id" && isString:
+			key, field.Value = "dd.span_id", datadogID(s)
+		case datadogReservedKeys[key] || key == "caller":
+			key = "fields." + key
+		}
+		buf.WriteByte(',')
+		writeJSONField(&buf, key, field.Value)
+	}
+	if entry.Stack != "" {
+		buf.WriteByte(',')
+		writeJSONField(&buf, "error.stack", entry.Stack)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+// datadogID converts an OpenTelemetry hex trace or span ID to the decimal
+// form Datadog correlates on, the lower 64 bits. Other IDs are kept as is
+func datadogID(id string) string {
+	if len(id) > 16 {
+		id = id[len(id)-16:]
+	}
+	n, err := strconv.ParseUint(id, 16, 64)
+	if err != nil {
+		return id
+	}
+	return strconv.FormatUint(n, 10)
+}
+
+// newDatadogFormatter returns the formatter of FormatDatadog, taking the
+// service from DD_SERVICE as the Datadog libraries do
+func newDatadogFormatter() *DatadogFormatter {
+	return &DatadogFormatter{Service: os.Getenv("DD_SERVICE")}
+}
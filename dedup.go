@@ -0,0 +1,101 @@
+package simplelog
+
+import (
+	"fmt"
+	"time"
+)
+
+// dedupState tracks the run of identical entries being suppressed
+type dedupState struct {
+	window  time.Duration
+	last    Entry
+	key     string
+	started time.Time
+	repeats int
+
+	// run numbers the runs, so that timer only ends the run it was set for
+	run   uint64
+	timer *time.Timer
+}
+
+// SetDuplicateSuppression collapses runs of consecutive entries with the same
+// level, message and fields logged within window of the first: repeats are
+// not written, and when the run ends a "last message repeated N times" entry
+// is written instead. A run ends with a different entry, once window has
+// passed, or on Flush and Close. A window of 0 turns suppression off
+func (l *Logger) SetDuplicateSuppression(window time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.endDuplicateRunLocked()
+	if window <= 0 {
+		l.dedup = nil
+		return
+	}
+	l.dedup = &dedupState{window: window}
+}
+
+// suppressDuplicate reports whether entry repeats the previous one and should
+// not be written, ending the previous run otherwise. l.mu must be held
+func (l *Logger) suppressDuplicate(entry *Entry) bool {
+	d := l.dedup
+	if d == nil {
+		return false
+	}
+	key := dedupKey(entry)
+	if key == d.key && entry.Time.Sub(d.started) < d.window {
+		d.repeats++
+		if d.timer == nil {
+			// Report the run when the window ends even if nothing else is logged
+			run := d.run
+			d.timer = time.AfterFunc(d.window-entry.Time.Sub(d.started), func() {
+				l.mu.Lock()
+				defer l.mu.Unlock()
+
+				if l.dedup == d && d.run == run {
+					l.endDuplicateRunLocked()
+				}
+			})
+		}
+		return true
+	}
+	l.endDuplicateRunLocked()
+	d.last, d.key, d.started = *entry, key, entry.Time
+	d.run++
+	return false
+}
+
+// endDuplicateRunLocked writes the summary of the suppressed run, if any.
+// l.mu must be held
+func (l *Logger) endDuplicateRunLocked() {
+	d := l.dedup
+	if d == nil || d.repeats == 0 {
+		return
+	}
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	summary := Entry{
+		Time:    time.Now(),
+		Level:   d.last.Level,
+		File:    d.last.File,
+		Line:    d.last.Line,
+		Message: fmt.Sprintf("last message repeated %d times", d.repeats),
+		Logger:  d.last.Logger,
+	}
+	if summary.Time.Before(l.lastTime) {
+		summary.Time = l.lastTime
+	}
+	d.repeats, d.key = 0, ""
+	l.writeLocked(&summary)
+}
+
+// dedupKey identifies an entry's level, message and fields
+func dedupKey(entry *Entry) string {
+	key := fmt.Sprintf("%d\x00%s", entry.Level, entry.Message)
+	for i, field := range entry.Fields {
+		key += "\x00" + field.Key + "=" + entry.fieldText(i, QuoteAlways)
+	}
+	return key
+}
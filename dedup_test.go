@@ -0,0 +1,51 @@
+package simplelog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDuplicateRunEndsWithDifferentEntry(t *testing.T) {
+	w := &gatedWriter{open: make(chan struct{})}
+	close(w.open)
+	l := NewWriter(INFO, w)
+	l.SetDuplicateSuppression(time.Minute)
+
+	for i := 0; i < 4; i++ {
+		l.Error("connection refused")
+	}
+	l.Info("recovered")
+
+	got := w.String()
+	if n := strings.Count(got, "connection refused"); n != 1 {
+		t.Errorf("repeated entry written %d times, want once:\n%s", n, got)
+	}
+	if i, j := strings.Index(got, "last message repeated 3 times"), strings.Index(got, "recovered"); i < 0 || j < i {
+		t.Errorf("want the summary before the next entry:\n%s", got)
+	}
+}
+
+func TestDuplicateRunEndsWhenWindowPasses(t *testing.T) {
+	w := &gatedWriter{open: make(chan struct{})}
+	close(w.open)
+	l := NewWriter(INFO, w)
+	l.SetDuplicateSuppression(30 * time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		l.Error("connection refused")
+	}
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(w.String(), "last message repeated 2 times") {
+		if time.Now().After(deadline) {
+			t.Fatalf("no summary once the window passed:\n%s", w.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// The next repeat starts a new run rather than being suppressed
+	l.Error("connection refused")
+	if n := strings.Count(w.String(), "connection refused"); n != 2 {
+		t.Errorf("entry written %d times, want 2:\n%s", n, w.String())
+	}
+}
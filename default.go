@@ -0,0 +1,85 @@
+package simplelog
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	defaultLogger atomic.Pointer[Logger]
+	defaultOnce   sync.Once
+)
+
+// Default returns the logger used by the package-level functions such as
+// Info. Unless replaced with SetDefault it logs INFO and above to stdout, as
+// New does without options
+func Default() *Logger {
+	defaultOnce.Do(func() {
+		defaultLogger.CompareAndSwap(nil, newLogger(INFO, nil))
+	})
+	return defaultLogger.Load()
+}
+
+// SetDefault makes l the logger used by the package-level functions
+func SetDefault(l *Logger) {
+	defaultLogger.Store(l)
+}
+
+func logDefault(level LogLevel, format string, args []interface{}) {
+	if l := Default(); l.enabled(level) {
+		l.output(1, level, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+// Trace logs a trace-level message with the default logger
+func Trace(format string, args ...interface{}) {
+	logDefault(TRACE, format, args)
+}
+
+// Debug logs a debug-level message with the default logger
+func Debug(format string, args ...interface{}) {
+	logDefault(DEBUG, format, args)
+}
+
+// Info logs an info-level message with the default logger
+func Info(format string, args ...interface{}) {
+	logDefault(INFO, format, args)
+}
+
+// Warn logs a warn-level message with the default logger
+func Warn(format string, args ...interface{}) {
+	logDefault(WARN, format, args)
+}
+
+// Error logs an error-level message with the default logger
+func Error(format string, args ...interface{}) {
+	logDefault(ERROR, format, args)
+}
+
+// Log logs a message at level with the default logger
+func Log(level LogLevel, format string, args ...interface{}) {
+	logDefault(level, format, args)
+}
+
+// Panic logs a panic-level message with the default logger and panics, see
+// Logger.Panic
+func Panic(format string, args ...interface{}) {
+	Default().WithCallerSkip(1).Panic(format, args...)
+}
+
+// Fatal logs a fatal-level message with the default logger and exits, see
+// Logger.Fatal
+func Fatal(format string, args ...interface{}) {
+	Default().WithCallerSkip(1).Fatal(format, args...)
+}
+
+// With returns the default logger with fields added, see Logger.With
+func With(keyvals ...interface{}) *Logger {
+	return Default().With(keyvals...)
+}
+
+// SetLevel sets the minimum level of the default logger
+func SetLevel(level LogLevel) {
+	Default().SetLevel(level)
+}
@@ -0,0 +1,160 @@
+package simplelog
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// NewFromEnv creates a Logger like New, configured by opts and then by these
+// environment variables, which take precedence when set:
+//
+//	SIMPLELOG_LEVEL     minimum level, e.g. debug, see ParseLevel
+//	SIMPLELOG_FORMAT    text, json, logfmt, gelf, gcp, datadog or rfc5424
+//	SIMPLELOG_CALLER    short, full or none, see ParseCallerMode
+//	SIMPLELOG_FILE      log file, see WithFile
+//	SIMPLELOG_MAX_SIZE  rotation size in bytes, or with a KB, MB or GB suffix
+//
+// It returns an error if a variable has an invalid value
+func NewFromEnv(opts ...Option) (*Logger, error) {
+	if v := os.Getenv("SIMPLELOG_LEVEL"); v != "" {
+		level, err := ParseLevel(v)
+		if err != nil {
+			return nil, fmt.Errorf("simplelog: SIMPLELOG_LEVEL: %w", err)
+		}
+		opts = append(opts, WithLevel(level))
+	}
+	if v := os.Getenv("SIMPLELOG_FORMAT"); v != "" {
+		format, err := ParseFormat(v)
+		if err != nil {
+			return nil, fmt.Errorf("simplelog: SIMPLELOG_FORMAT: %w", err)
+		}
+		opts = append(opts, WithFormat(format))
+	}
+	if v := os.Getenv("SIMPLELOG_CALLER"); v != "" {
+		mode, err := ParseCallerMode(v)
+		if err != nil {
+			return nil, fmt.Errorf("simplelog: SIMPLELOG_CALLER: %w", err)
+		}
+		opts = append(opts, WithCallerMode(mode))
+	}
+	if v := os.Getenv("SIMPLELOG_FILE"); v != "" {
+		opts = append(opts, WithFile(v))
+	}
+	if v := os.Getenv("SIMPLELOG_MAX_SIZE"); v != "" {
+		size, err := parseSize(v)
+		if err != nil {
+			return nil, fmt.Errorf("simplelog: SIMPLELOG_MAX_SIZE: %w", err)
+		}
+		opts = append(opts, WithMaxFileSize(size))
+	}
+	return New(opts...)
+}
+
+// ParseFormat returns the built-in format named s: text, json, logfmt, gelf,
+// gcp, datadog or rfc5424, ignoring case
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	case "logfmt":
+		return FormatLogfmt, nil
+	case "gelf":
+		return FormatGELF, nil
+	case "gcp":
+		return FormatGCP, nil
+	case "datadog":
+		return FormatDatadog, nil
+	case "rfc5424":
+		return FormatRFC5424, nil
+	}
+	return FormatText, fmt.Errorf("unknown format %q", s)
+}
+
+// parseSize parses a byte count such as 1048576, 512KB or 10MB
+func parseSize(v string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(v))
+	unit := int64(1)
+	for _, u := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(s, u.suffix) {
+			s, unit = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q", v)
+	}
+	return n * unit, nil
+}
+
+// MarshalText returns the format's name, as accepted by ParseFormat
+func (f Format) MarshalText() ([]byte, error) {
+	switch f {
+	case FormatJSON:
+		return []byte("json"), nil
+	case FormatLogfmt:
+		return []byte("logfmt"), nil
+	case FormatGELF:
+		return []byte("gelf"), nil
+	case FormatGCP:
+		return []byte("gcp"), nil
+	case FormatDatadog:
+		return []byte("datadog"), nil
+	case FormatRFC5424:
+		return []byte("rfc5424"), nil
+	default:
+		return []byte("text"), nil
+	}
+}
+
+// UnmarshalText parses a format name with ParseFormat
+func (f *Format) UnmarshalText(text []byte) error {
+	format, err := ParseFormat(string(text))
+	if err != nil {
+		return err
+	}
+	*f = format
+	return nil
+}
+
+// ParseCallerMode returns the caller mode named s: short, full or none
+func ParseCallerMode(s string) (CallerMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "short":
+		return CallerShort, nil
+	case "full":
+		return CallerFull, nil
+	case "none":
+		return CallerNone, nil
+	}
+	return CallerShort, fmt.Errorf("unknown caller mode %q", s)
+}
+
+// MarshalText returns the caller mode's name, as accepted by ParseCallerMode
+func (m CallerMode) MarshalText() ([]byte, error) {
+	switch m {
+	case CallerFull:
+		return []byte("full"), nil
+	case CallerNone:
+		return []byte("none"), nil
+	default:
+		return []byte("short"), nil
+	}
+}
+
+// UnmarshalText parses a caller mode name with ParseCallerMode
+func (m *CallerMode) UnmarshalText(text []byte) error {
+	mode, err := ParseCallerMode(string(text))
+	if err != nil {
+		return err
+	}
+	*m = mode
+	return nil
+}
@@ -0,0 +1,53 @@
+package simplelog
+
+// ErrorHandler is called with the errors a logger runs into while writing
+// entries: failed writes to the console, outputs, log file and sinks, and
+// failed rotations
+type ErrorHandler func(err error)
+
+// maxPendingErrors bounds the errors held for the handler between entries
+const maxPendingErrors = 64
+
+// SetErrorHandler sets the function called when writing an entry fails.
+// It is called outside the logger's lock, after the entry, so it may log
+// through the logger, though an entry that fails again calls it again.
+// Without a handler write errors are ignored and a rotation that starts
+// failing is reported once on stderr. nil removes it
+func (l *Logger) SetErrorHandler(h ErrorHandler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.errHandler = h
+}
+
+// WithErrorHandler sets the logger's error handler, see SetErrorHandler
+func WithErrorHandler(h ErrorHandler) Option {
+	return func(c *config) {
+		c.errHandler = h
+	}
+}
+
+// pendError holds err for the error handler, if there is one. l.mu must be held
+func (l *Logger) pendError(err error) {
+	if l.errHandler == nil || len(l.errs) >= maxPendingErrors {
+		return
+	}
+	l.errs = append(l.errs, err)
+	l.errPending.Store(true)
+}
+
+// reportErrors passes the held errors to the error handler
+func (l *Logger) reportErrors() {
+	l.mu.Lock()
+	errs, h := l.errs, l.errHandler
+	l.errs = nil
+	l.errPending.Store(false)
+	l.mu.Unlock()
+
+	if h == nil {
+		return
+	}
+	for _, err := range errs {
+		h(err)
+	}
+}
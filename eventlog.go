@@ -0,0 +1,96 @@
+//go:build windows
+
+package simplelog
+
+import (
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// EventLogSink is a Sink that writes entries to the Windows Event Log under an
+// event source. Only WARN and ERROR entries are written unless SetLevel says
+// otherwise; WARN becomes a warning event and ERROR an error event
+type EventLogSink struct {
+	mu      sync.Mutex
+	log     *eventlog.Log
+	level   LogLevel
+	eventID uint32
+}
+
+// NewEventLogSink opens the Event Log for source. If install is true the
+// source is registered first, which needs administrator rights; an existing
+// registration is left alone
+func NewEventLogSink(source string, install bool) (*EventLogSink, error) {
+	if install {
+		err := eventlog.InstallAsEventCreate(source, eventlog.Error|eventlog.Warning|eventlog.Info)
+		if err != nil && !isSourceExists(err) {
+			return nil, err
+		}
+	}
+	log, err := eventlog.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	return &EventLogSink{log: log, level: WARN, eventID: 1}, nil
+}
+
+// WithEventLog sends WARN and ERROR entries to the Windows Event Log as well,
+// see NewEventLogSink. New returns an error if the source can't be opened
+func WithEventLog(source string, install bool) Option {
+	return func(c *config) {
+		c.sinks = append(c.sinks, func() (Sink, error) {
+			return NewEventLogSink(source, install)
+		})
+	}
+}
+
+// SetLevel sets the lowest level written, WARN by default
+func (s *EventLogSink) SetLevel(level LogLevel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.level = level
+}
+
+// SetEventID sets the event ID recorded with each entry, 1 by default
+func (s *EventLogSink) SetEventID(id uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.eventID = id
+}
+
+// WriteEntry writes entry as an event if its level is high enough
+func (s *EventLogSink) WriteEntry(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry.Level < s.level {
+		return nil
+	}
+	b, err := messageFormatter{}.Format(entry)
+	if err != nil {
+		return err
+	}
+	switch {
+	case entry.Level >= ERROR:
+		return s.log.Error(s.eventID, string(b))
+	case entry.Level >= WARN:
+		return s.log.Warning(s.eventID, string(b))
+	default:
+		return s.log.Info(s.eventID, string(b))
+	}
+}
+
+// Close closes the Event Log handle
+func (s *EventLogSink) Close() error {
+	return s.log.Close()
+}
+
+// isSourceExists reports whether err is InstallAsEventCreate refusing to
+// overwrite an existing source registration
+func isSourceExists(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "registry key already exists")
+}
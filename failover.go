@@ -0,0 +1,78 @@
+package simplelog
+
+import (
+	"io"
+	"time"
+)
+
+// SetFailover sets a secondary writer, e.g. os.Stderr, for entries the log
+// file or a sink fails to take. After a failure the failed output is left
+// alone for retry, with its entries going to w instead; then the log file is
+// reopened, or the sink tried again, and used if it works. nil turns failover
+// off
+func (l *Logger) SetFailover(w io.Writer, retry time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.failover = w
+	l.retryEvery = retry
+	if w == nil {
+		l.fileFailed = false
+		l.sinkRetryAt = nil
+	}
+}
+
+// writeFileFailover writes a formatted entry to the log file, or to the
+// failover writer while the file is failing. l.mu must be held
+func (l *Logger) writeFileFailover(b []byte) error {
+	if l.failover == nil {
+		return l.writeFile(b)
+	}
+
+	now := time.Now()
+	if l.fileFailed {
+		if now.Before(l.fileRetryAt) {
+			l.failover.Write(b)
+			return nil
+		}
+		if l.file != nil {
+			l.file.Close()
+		}
+		if err := l.openLog(now); err != nil {
+			l.fileRetryAt = now.Add(l.retryEvery)
+			l.failover.Write(b)
+			return err
+		}
+	}
+
+	if err := l.writeFile(b); err != nil {
+		l.fileFailed = true
+		l.fileRetryAt = now.Add(l.retryEvery)
+		l.failover.Write(b)
+		return err
+	}
+	return nil
+}
+
+// writeSinkFailover writes entry to the i'th sink, or its formatted form b to
+// the failover writer while the sink is failing. l.mu must be held
+func (l *Logger) writeSinkFailover(i int, s Sink, entry Entry, b []byte) error {
+	if l.failover == nil {
+		return s.WriteEntry(entry)
+	}
+	for len(l.sinkRetryAt) <= i {
+		l.sinkRetryAt = append(l.sinkRetryAt, time.Time{})
+	}
+
+	now := time.Now()
+	if now.Before(l.sinkRetryAt[i]) {
+		l.failover.Write(b)
+		return nil
+	}
+	if err := s.WriteEntry(entry); err != nil {
+		l.sinkRetryAt[i] = now.Add(l.retryEvery)
+		l.failover.Write(b)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package simplelog
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Field is a structured key/value pair attached to an entry
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Any returns a field with an arbitrary value
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// String returns a field with a string value
+func String(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int returns a field with an int value
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Bool returns a field with a bool value
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Err returns an "error" field holding err
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+// FieldOrder controls the order in which an entry's fields are written
+type FieldOrder int
+
+const (
+	// FieldOrderInsertion writes fields in the order they were added
+	FieldOrderInsertion FieldOrder = iota
+	// FieldOrderSorted writes fields sorted by key
+	FieldOrderSorted
+)
+
+// withField returns a copy of fields with key set to value. An existing key
+// keeps its position so that insertion order stays stable
+func withField(fields []Field, key string, value interface{}) []Field {
+	out := make([]Field, len(fields), len(fields)+1)
+	copy(out, fields)
+	for i := range out {
+		if out[i].Key == key {
+			out[i].Value = value
+			return out
+		}
+	}
+	return append(out, Field{Key: key, Value: value})
+}
+
+// sortedFields returns a copy of fields sorted by key
+func sortedFields(fields []Field) []Field {
+	if len(fields) < 2 {
+		return fields
+	}
+	out := make([]Field, len(fields))
+	copy(out, fields)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// QuoteMode controls when the text format quotes string field values
+type QuoteMode int
+
+const (
+	// QuoteWhenNeeded quotes values that are empty or contain spaces, quotes,
+	// equals signs or control characters, as logfmt does
+	QuoteWhenNeeded QuoteMode = iota
+	// QuoteAlways quotes every string value
+	QuoteAlways
+	// QuoteNever writes string values as they are
+	QuoteNever
+)
+
+// textValue renders a field value for the text format. Strings, errors and
+// Stringers are quoted according to mode; other values are never quoted
+func textValue(v interface{}, mode QuoteMode) string {
+	return quoteText(valueText(v), mode)
+}
+
+// encodedValue is a field value rendered for the text format ahead of time,
+// before quoting
+type encodedValue struct {
+	s        string
+	quotable bool
+}
+
+// valueText renders v for the text format, reporting whether it may be quoted
+func valueText(v interface{}) encodedValue {
+	switch v := v.(type) {
+	case string:
+		return encodedValue{v, true}
+	case error:
+		return encodedValue{v.Error(), true}
+	case fmt.Stringer:
+		return encodedValue{v.String(), true}
+	default:
+		return encodedValue{fmt.Sprint(v), false}
+	}
+}
+
+// quoteText quotes an encoded value according to mode
+func quoteText(v encodedValue, mode QuoteMode) string {
+	if !v.quotable {
+		return v.s
+	}
+	switch mode {
+	case QuoteAlways:
+		return strconv.Quote(v.s)
+	case QuoteNever:
+		return v.s
+	}
+	if needsQuote(v.s) {
+		return strconv.Quote(v.s)
+	}
+	return v.s
+}
+
+// appendText appends the result of quoteText to b
+func appendText(b []byte, v encodedValue, mode QuoteMode) []byte {
+	if v.quotable && (mode == QuoteAlways || mode != QuoteNever && needsQuote(v.s)) {
+		return strconv.AppendQuote(b, v.s)
+	}
+	return append(b, v.s...)
+}
+
+// encodeFields renders the values of fields for the text format
+func encodeFields(fields []Field) []encodedValue {
+	encoded := make([]encodedValue, len(fields))
+	for i, field := range fields {
+		encoded[i] = valueText(field.Value)
+	}
+	return encoded
+}
+
+// hasField reports whether fields has one named key
+func hasField(fields []Field, key string) bool {
+	for _, field := range fields {
+		if field.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// keyvalFields converts alternating keys and values to fields. Field values
+// are used as they are; a key that isn't a string is reported as !BADKEY, and
+// a missing final value as nil
+func keyvalFields(keyvals []interface{}) []Field {
+	var fields []Field
+	for i := 0; i < len(keyvals); i++ {
+		switch k := keyvals[i].(type) {
+		case Field:
+			fields = append(fields, k)
+		case string:
+			var v interface{}
+			if i+1 < len(keyvals) {
+				v = keyvals[i+1]
+				i++
+			}
+			fields = append(fields, Field{Key: k, Value: v})
+		default:
+			fields = append(fields, Field{Key: "!BADKEY", Value: k})
+		}
+	}
+	return fields
+}
+
+func needsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	return strings.ContainsFunc(s, func(r rune) bool {
+		return r <= ' ' || r == '"' || r == '=' || r == 0x7f
+	})
+}
@@ -0,0 +1,50 @@
+package simplelog
+
+import (
+	"errors"
+	"testing"
+)
+
+type stringer string
+
+func (s stringer) String() string { return string(s) }
+
+func TestTextValueQuoting(t *testing.T) {
+	tests := []struct {
+		value  interface{}
+		needed string
+		always string
+		never  string
+	}{
+		{"plain", `plain`, `"plain"`, `plain`},
+		{"two words", `"two words"`, `"two words"`, `two words`},
+		{"", `""`, `""`, ``},
+		{`say "hi"`, `"say \"hi\""`, `"say \"hi\""`, `say "hi"`},
+		{"a=b", `"a=b"`, `"a=b"`, `a=b`},
+		{"line\nbreak", `"line\nbreak"`, `"line\nbreak"`, "line\nbreak"},
+		{"tab\there", `"tab\there"`, `"tab\there"`, "tab\there"},
+		{"del\x7f", `"del\x7f"`, `"del\x7f"`, "del\x7f"},
+		{"héllo", `héllo`, `"héllo"`, `héllo`},
+		{errors.New("not found"), `"not found"`, `"not found"`, `not found`},
+		{stringer("ok"), `ok`, `"ok"`, `ok`},
+		// Values that aren't strings are never quoted
+		{42, `42`, `42`, `42`},
+		{true, `true`, `true`, `true`},
+		{[]string{"a b"}, `[a b]`, `[a b]`, `[a b]`},
+	}
+	for _, tt := range tests {
+		for mode, want := range map[QuoteMode]string{
+			QuoteWhenNeeded: tt.needed,
+			QuoteAlways:     tt.always,
+			QuoteNever:      tt.never,
+		} {
+			if got := textValue(tt.value, mode); got != want {
+				t.Errorf("textValue(%#v, %d) = %s, want %s", tt.value, mode, got, want)
+			}
+			v := valueText(tt.value)
+			if got := string(appendText(nil, v, mode)); got != want {
+				t.Errorf("appendText(%#v, %d) = %s, want %s", tt.value, mode, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package simplelog
+
+import (
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// Entry holds a single log record as it is handed to a Formatter
+type Entry struct {
+	Time    time.Time
+	Level   LogLevel
+	File    string
+	Line    int
+	Message string
+	Fields  []Field
+	Stack   string
+
+	// Function is the calling function, if enabled with SetCallerFunction
+	Function string
+	// Logger is the name of the logger, see Logger.Named
+	Logger string
+
+	// encoded holds the text form of the leading fields, bound with
+	// Logger.With and encoded once. Anything changing those fields must clear it
+	encoded []encodedValue
+}
+
+// fieldText returns the text form of entry's i'th field value
+func (e Entry) fieldText(i int, mode QuoteMode) string {
+	if i < len(e.encoded) {
+		return quoteText(e.encoded[i], mode)
+	}
+	return textValue(e.Fields[i].Value, mode)
+}
+
+// appendFieldText appends the text form of entry's i'th field value to b
+func (e Entry) appendFieldText(b []byte, i int, mode QuoteMode) []byte {
+	if i < len(e.encoded) {
+		return appendText(b, e.encoded[i], mode)
+	}
+	return appendText(b, valueText(e.Fields[i].Value), mode)
+}
+
+// caller returns entry's file:line followed by the function if recorded, or
+// "" if the caller wasn't looked up
+func (e Entry) caller() string {
+	return string(e.appendCaller(nil))
+}
+
+// appendCaller appends the result of caller to b
+func (e Entry) appendCaller(b []byte) []byte {
+	if e.File == "" {
+		return b
+	}
+	b = append(b, e.File...)
+	b = append(b, ':')
+	b = strconv.AppendInt(b, int64(e.Line), 10)
+	if e.Function != "" {
+		b = append(b, ' ')
+		b = append(b, e.Function...)
+	}
+	return b
+}
+
+// Formatter renders an Entry into the bytes written to the log outputs
+type Formatter interface {
+	Format(entry Entry) ([]byte, error)
+}
+
+// Format selects one of the built-in output formats
+type Format int
+
+const (
+	// FormatText is the default single-line text format
+	FormatText Format = iota
+	// FormatGELF is GELF 1.1 JSON, as ingested by Graylog
+	FormatGELF
+	// FormatJSON is one JSON object per line
+	FormatJSON
+	// FormatLogfmt is logfmt key=value pairs
+	FormatLogfmt
+	// FormatGCP is the structured JSON of Google Cloud Logging, see GCPFormatter
+	FormatGCP
+	// FormatDatadog is JSON with Datadog's reserved attributes, see DatadogFormatter
+	FormatDatadog
+	// FormatRFC5424 is RFC 5424 syslog messages, see RFC5424Formatter
+	FormatRFC5424
+)
+
+// FormatterFunc adapts an ordinary function to the Formatter interface
+type FormatterFunc func(entry Entry) ([]byte, error)
+
+// Format calls fn(entry)
+func (fn FormatterFunc) Format(entry Entry) ([]byte, error) {
+	return fn(entry)
+}
+
+// DefaultTimeFormat is the time format used by the text format unless changed
+const DefaultTimeFormat = "2006-01-02 15:04:05"
+
+// TextFormatter is the built-in single-line text format:
+//
+//	[2024-01-02 10:00:00] INFO main.go:12: user logged in user_id=42
+type TextFormatter struct {
+	// TimeFormat is the layout of the timestamp; it defaults to DefaultTimeFormat
+	TimeFormat string
+	// Quote controls when string field values are quoted
+	Quote QuoteMode
+
+	color bool
+	icon  string
+}
+
+func (f TextFormatter) Format(entry Entry) ([]byte, error) {
+	return f.appendFormat(nil, entry), nil
+}
+
+// appendFormat appends the formatted entry to b, so that the logger can
+// reuse its buffers
+func (f TextFormatter) appendFormat(b []byte, entry Entry) []byte {
+	layout := f.TimeFormat
+	if layout == "" {
+		layout = DefaultTimeFormat
+	}
+	if f.icon != "" {
+		b = append(b, f.icon...)
+		b = append(b, ' ')
+	}
+	b = append(b, '[')
+	b = entry.Time.AppendFormat(b, layout)
+	b = append(b, "] "...)
+	level := levelToString(entry.Level)
+	if f.color {
+		level = colorize(entry.Level, level)
+	}
+	b = append(b, level...)
+	b = append(b, ' ')
+	if entry.Logger != "" {
+		b = append(b, '[')
+		b = append(b, entry.Logger...)
+		b = append(b, "] "...)
+	}
+	if entry.File != "" {
+		b = entry.appendCaller(b)
+		b = append(b, ": "...)
+	}
+	b = append(b, entry.Message...)
+	for i, field := range entry.Fields {
+		b = append(b, ' ')
+		b = append(b, field.Key...)
+		b = append(b, '=')
+		b = entry.appendFieldText(b, i, f.Quote)
+	}
+	if entry.Stack != "" {
+		b = append(b, '\n')
+		b = append(b, entry.Stack...)
+	}
+	return append(b, '\n')
+}
+
+// messageFormatter renders an entry without its time and level, for sinks such
+// as syslog that record those themselves
+type messageFormatter struct{}
+
+func (messageFormatter) Format(entry Entry) ([]byte, error) {
+	line := entry.Message
+	if caller := entry.caller(); caller != "" {
+		line = caller + ": " + line
+	}
+	if entry.Logger != "" {
+		line = "[" + entry.Logger + "] " + line
+	}
+	for i, field := range entry.Fields {
+		line += " " + field.Key + "=" + entry.fieldText(i, QuoteWhenNeeded)
+	}
+	if entry.Stack != "" {
+		line += "\n" + entry.Stack
+	}
+	return []byte(line), nil
+}
+
+const truncatedSuffix = "...[truncated]"
+
+// fitEntry shrinks entry's message so that its formatted form is at most max
+// bytes. It reports false if the entry can't be made to fit
+func fitEntry(f Formatter, entry Entry, formatted []byte, max int) (Entry, []byte, bool) {
+	excess := len(formatted) - max
+	for excess > 0 {
+		keep := len(entry.Message) - excess - len(truncatedSuffix)
+		if keep <= 0 {
+			return entry, nil, false
+		}
+		for keep > 0 && !utf8.RuneStart(entry.Message[keep]) {
+			keep--
+		}
+		entry.Message = entry.Message[:keep] + truncatedSuffix
+
+		var err error
+		if formatted, err = f.Format(entry); err != nil {
+			return entry, nil, false
+		}
+		excess = len(formatted) - max
+	}
+	return entry, formatted, true
+}
@@ -0,0 +1,83 @@
+package simplelog
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+)
+
+// GCPFormatter renders entries as the structured JSON that Google Cloud
+// Logging parses from stdout on GKE, Cloud Run and Cloud Functions, with
+// the severity, source location and trace in their special fields
+type GCPFormatter struct {
+	// ProjectID qualifies trace IDs, which Cloud Logging only links to
+	// Cloud Trace as projects/PROJECT_ID/traces/TRACE_ID. Without it the
+	// trace_id field is written like any other
+	ProjectID string
+}
+
+const gcpKeyPrefix = "logging.googleapis.com/"
+
+var gcpReservedKeys = map[string]bool{"severity": true, "time": true, "message": true, "logger": true, "stack_trace": true}
+
+func (f *GCPFormatter) Format(entry Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	writeJSONField(&buf, "severity", gcpSeverity(entry.Level))
+	buf.WriteByte(',')
+	writeJSONField(&buf, "time", entry.Time.Format(time.RFC3339Nano))
+	buf.WriteByte(',')
+	writeJSONField(&buf, "message", entry.Message)
+	if entry.Logger != "" {
+		buf.WriteByte(',')
+		writeJSONField(&buf, "logger", entry.Logger)
+	}
+	if entry.File != "" {
+		buf.WriteByte(',')
+		loc := map[string]string{"file": entry.File, "line": strconv.Itoa(entry.Line)}
+		if entry.Function != "" {
+			loc["function"] = entry.Function
+		}
+		writeJSONField(&buf, gcpKeyPrefix+"sourceLocation", loc)
+	}
+	for _, field := range entry.Fields {
+		key := field.Key
+		s, isString := field.Value.(string)
+		switch {
+		case f.ProjectID != "" && key == "trace_id" && isString:
+			key, field.Value = gcpKeyPrefix+"trace", "projects/"+f.ProjectID+"/traces/"+s
+		case f.ProjectID != "" && key == "span_id" && isString:
+			key = gcpKeyPrefix + "spanId"
+		case gcpReservedKeys[key]:
+			key = "fields." + key
+		}
+		buf.WriteByte(',')
+		writeJSONField(&buf, key, field.Value)
+	}
+	if entry.Stack != "" {
+		// Error Reporting picks up stack traces from this field
+		buf.WriteByte(',')
+		writeJSONField(&buf, "stack_trace", entry.Message+"\n"+entry.Stack)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+// gcpSeverity maps a LogLevel to a Cloud Logging severity, following the
+// syslog severities of syslogSeverity
+func gcpSeverity(level LogLevel) string {
+	switch syslogSeverity(level) {
+	case 7:
+		return "DEBUG"
+	case 6:
+		return "INFO"
+	case 5:
+		return "NOTICE"
+	case 4:
+		return "WARNING"
+	case 3:
+		return "ERROR"
+	default:
+		return "CRITICAL"
+	}
+}
@@ -0,0 +1,105 @@
+package simplelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// GELFFormatter renders entries as GELF 1.1 JSON for Graylog
+type GELFFormatter struct {
+	// Host is reported in the host field; it defaults to os.Hostname()
+	Host string
+}
+
+var gelfInvalidKey = regexp.MustCompile(`[^\w.\-]`)
+
+// hostname returns os.Hostname(), looked up once as formatters need it for
+// every entry
+var hostname = sync.OnceValue(func() string {
+	host, _ := os.Hostname()
+	return host
+})
+
+func (f *GELFFormatter) Format(entry Entry) ([]byte, error) {
+	host := f.Host
+	if host == "" {
+		host = hostname()
+	}
+
+	msg := map[string]interface{}{
+		"version":       "1.1",
+		"host":          host,
+		"short_message": entry.Message,
+		"timestamp":     float64(entry.Time.UnixMicro()) / 1e6,
+		"level":         syslogSeverity(entry.Level),
+	}
+	if entry.File != "" {
+		msg["_file"] = entry.File
+		msg["_line"] = entry.Line
+	}
+	if entry.Function != "" {
+		msg["_function"] = entry.Function
+	}
+	if entry.Logger != "" {
+		msg["_logger"] = entry.Logger
+	}
+	if i := strings.IndexByte(entry.Message, '\n'); i >= 0 {
+		msg["short_message"] = entry.Message[:i]
+		msg["full_message"] = entry.Message
+	}
+	for _, field := range entry.Fields {
+		msg[gelfFieldName(field.Key)] = gelfValue(field.Value)
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// gelfFieldName returns the underscore-prefixed additional field name for key.
+// GELF forbids the additional field _id, so id is written as __id
+func gelfFieldName(key string) string {
+	key = gelfInvalidKey.ReplaceAllString(key, "_")
+	if key == "id" {
+		key = "_id"
+	}
+	return "_" + key
+}
+
+// gelfValue converts v to a string or number, the only types GELF allows
+func gelfValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	case error:
+		return v.Error()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// syslogSeverity maps a LogLevel to its RFC 5424 numeric severity. Custom
+// levels take the severity of the built-in level below them, except that
+// those between INFO and WARN are notices
+func syslogSeverity(level LogLevel) int {
+	switch {
+	case level < INFO:
+		return 7
+	case level == INFO:
+		return 6
+	case level < WARN:
+		return 5
+	case level < ERROR:
+		return 4
+	case level < PANIC:
+		return 3
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,124 @@
+package simplelog
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGELFFormatterRequiredFields(t *testing.T) {
+	f := &GELFFormatter{Host: "web-1"}
+	entry := Entry{
+		Time:    time.Date(2024, 1, 2, 10, 0, 0, 500000000, time.UTC),
+		Level:   WARN,
+		File:    "main.go",
+		Line:    12,
+		Message: "disk almost full\nused 95%",
+		Fields:  []Field{{Key: "id", Value: 7}, {Key: "err", Value: errors.New("boom")}},
+	}
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(b), "}\n") {
+		t.Errorf("output %q doesn't end in a single JSON object and newline", b)
+	}
+
+	var msg map[string]interface{}
+	if err := json.Unmarshal(b, &msg); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]interface{}{
+		"version":       "1.1",
+		"host":          "web-1",
+		"short_message": "disk almost full",
+		"full_message":  "disk almost full\nused 95%",
+		"timestamp":     1704189600.5,
+		"level":         float64(4),
+		"_file":         "main.go",
+		"_line":         float64(12),
+		"__id":          float64(7),
+		"_err":          "boom",
+	} {
+		if got, ok := msg[key]; !ok {
+			t.Errorf("missing %s", key)
+		} else if got != want {
+			t.Errorf("%s = %#v (%T), want %#v (%T)", key, got, got, want, want)
+		}
+	}
+	if _, ok := msg["_id"]; ok {
+		t.Error("reserved _id field was written")
+	}
+}
+
+func TestGELFFormatterDefaultsHost(t *testing.T) {
+	b, err := (&GELFFormatter{}).Format(Entry{Time: time.Now(), Level: INFO, Message: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg map[string]interface{}
+	if err := json.Unmarshal(b, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if host, _ := msg["host"].(string); host == "" {
+		t.Errorf("host = %#v, want the hostname", msg["host"])
+	}
+}
+
+func TestGELFLoggingAfterClose(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	l, err := New(WithGELF("udp", pc.LocalAddr().String()), WithStdout(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("before close")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("after close")
+	if len(l.sinks) != 0 {
+		t.Errorf("closed sink still registered: %v", l.sinks)
+	}
+}
+
+func TestGELFTCPDoesNotRedialAfterClose(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	s, err := NewGELFSink("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	(<-accepted).Close()
+	s.Close()
+	if err := s.WriteEntry(Entry{Level: INFO, Message: "after close"}); !errors.Is(err, errGELFClosed) {
+		t.Errorf("WriteEntry after Close = %v, want %v", err, errGELFClosed)
+	}
+	select {
+	case conn := <-accepted:
+		conn.Close()
+		t.Error("sink reconnected after Close")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
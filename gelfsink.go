@@ -0,0 +1,195 @@
+package simplelog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// GELFCompression selects how GELFSink compresses UDP messages
+type GELFCompression int
+
+const (
+	// GELFCompressGzip is the default
+	GELFCompressGzip GELFCompression = iota
+	GELFCompressZlib
+	GELFCompressNone
+)
+
+// GELF chunking limits, see https://go2docs.graylog.org/current/getting_in_log_data/gelf.html
+const (
+	gelfChunkHeader  = 12
+	gelfMaxChunks    = 128
+	defaultGELFChunk = 1420
+)
+
+var errGELFClosed = errors.New("simplelog: GELF sink is closed")
+
+// GELFSink is a Sink that sends entries to a Graylog GELF input over UDP or
+// TCP, rendered by a GELFFormatter. UDP messages are compressed and split
+// into chunks when larger than a datagram; TCP messages are sent
+// uncompressed and null-terminated, as GELF TCP requires
+type GELFSink struct {
+	mu          sync.Mutex
+	network     string
+	addr        string
+	conn        net.Conn
+	formatter   *GELFFormatter
+	compression GELFCompression
+	chunkSize   int
+	closed      bool
+}
+
+// NewGELFSink connects to the GELF input at addr over network, "udp" or "tcp"
+func NewGELFSink(network, addr string) (*GELFSink, error) {
+	if network != "udp" && network != "tcp" {
+		return nil, errors.New("simplelog: GELF network must be udp or tcp")
+	}
+	conn, err := net.DialTimeout(network, addr, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &GELFSink{
+		network:   network,
+		addr:      addr,
+		conn:      conn,
+		formatter: &GELFFormatter{},
+		chunkSize: defaultGELFChunk,
+	}, nil
+}
+
+// WithGELF sends entries to a GELF input as well, see NewGELFSink. New
+// returns an error if the input can't be reached
+func WithGELF(network, addr string) Option {
+	return func(c *config) {
+		c.sinks = append(c.sinks, func() (Sink, error) {
+			return NewGELFSink(network, addr)
+		})
+	}
+}
+
+// SetHost sets the host field of the messages, os.Hostname() by default
+func (s *GELFSink) SetHost(host string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.formatter = &GELFFormatter{Host: host}
+}
+
+// SetCompression sets how UDP messages are compressed
+func (s *GELFSink) SetCompression(c GELFCompression) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.compression = c
+}
+
+// SetChunkSize sets the largest UDP datagram sent, 1420 bytes by default to
+// fit a typical MTU. Messages needing more than 128 chunks are dropped
+func (s *GELFSink) SetChunkSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chunkSize = size
+}
+
+// WriteEntry sends entry. Over TCP a failed write is retried once on a new
+// connection, unless the sink has been closed
+func (s *GELFSink) WriteEntry(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errGELFClosed
+	}
+	b, err := s.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	b = bytes.TrimSuffix(b, []byte{'\n'})
+
+	if s.network == "udp" {
+		return s.writeUDP(b)
+	}
+	b = append(b, 0)
+	if s.conn != nil {
+		if _, err = s.conn.Write(b); err == nil {
+			return nil
+		}
+		s.conn.Close()
+	}
+	if s.conn, err = net.DialTimeout(s.network, s.addr, 10*time.Second); err != nil {
+		return err
+	}
+	_, err = s.conn.Write(b)
+	return err
+}
+
+// writeUDP compresses b and sends it as one datagram, or as chunks if it
+// doesn't fit. s.mu must be held
+func (s *GELFSink) writeUDP(b []byte) error {
+	if s.conn == nil {
+		return errGELFClosed
+	}
+	if s.compression != GELFCompressNone {
+		var buf bytes.Buffer
+		var w interface {
+			Write(p []byte) (int, error)
+			Close() error
+		}
+		if s.compression == GELFCompressZlib {
+			w = zlib.NewWriter(&buf)
+		} else {
+			w = gzip.NewWriter(&buf)
+		}
+		w.Write(b)
+		if err := w.Close(); err != nil {
+			return err
+		}
+		b = buf.Bytes()
+	}
+
+	if len(b) <= s.chunkSize {
+		_, err := s.conn.Write(b)
+		return err
+	}
+
+	size := s.chunkSize - gelfChunkHeader
+	count := (len(b) + size - 1) / size
+	if count > gelfMaxChunks {
+		return errors.New("simplelog: entry too large for GELF")
+	}
+	var id [8]byte
+	rand.Read(id[:])
+	chunk := make([]byte, 0, s.chunkSize)
+	for i := 0; i < count; i++ {
+		end := min((i+1)*size, len(b))
+		chunk = append(chunk[:0], 0x1e, 0x0f)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, b[i*size:end]...)
+		if _, err := s.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the connection to the input
+func (s *GELFSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
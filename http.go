@@ -0,0 +1,270 @@
+package simplelog
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPMiddleware wraps next to log each request the way GinMiddleware does,
+// for net/http servers and routers built on it such as chi and gorilla/mux.
+// Requests are logged at the level chosen by DefaultStatusLevel
+func (l *Logger) HTTPMiddleware(next http.Handler) http.Handler {
+	return l.HTTPMiddlewareWith()(next)
+}
+
+// HTTPOption configures the middleware returned by HTTPMiddlewareWith
+type HTTPOption func(*httpConfig)
+
+type httpConfig struct {
+	format func(req Request) string
+}
+
+// HTTPFormat sets the function building the message for each request in
+// place of the default "Request: ..." line, e.g. CombinedLogFormat
+func HTTPFormat(fn func(req Request) string) HTTPOption {
+	return func(cfg *httpConfig) {
+		cfg.format = fn
+	}
+}
+
+// HTTPMiddlewareWith returns a middleware like HTTPMiddleware configured by opts
+func (l *Logger) HTTPMiddlewareWith(opts ...HTTPOption) func(next http.Handler) http.Handler {
+	var cfg httpConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return func(next http.Handler) http.Handler {
+		return l.httpMiddleware(next, cfg)
+	}
+}
+
+func (l *Logger) httpMiddleware(next http.Handler, cfg httpConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		path := r.URL.Path
+		if r.URL.RawQuery != "" {
+			path = path + "?" + r.URL.RawQuery
+		}
+
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+
+		latency := time.Since(start)
+		level := DefaultStatusLevel(sw.Status())
+		if !l.enabled(level) {
+			return
+		}
+		user, _, _ := r.BasicAuth()
+		l.Ctx(r.Context()).LogRequestFormat(level, Request{
+			Method:    r.Method,
+			Path:      path,
+			Status:    sw.Status(),
+			ClientIP:  clientIP(r),
+			Latency:   latency,
+			UserAgent: r.UserAgent(),
+			Time:      start,
+			Proto:     r.Proto,
+			Size:      sw.size,
+			Referer:   r.Referer(),
+			User:      user,
+		}, cfg.format)
+	})
+}
+
+// Request describes a served HTTP request, for middlewares outside this
+// package to log with LogRequest
+type Request struct {
+	Method string
+	// Path includes the query string, if any
+	Path      string
+	Status    int
+	ClientIP  string
+	Latency   time.Duration
+	UserAgent string
+	// Errors describes any errors the handlers returned
+	Errors string
+
+	// Time is when the request was received
+	Time time.Time
+	// Proto is the protocol, e.g. "HTTP/1.1"
+	Proto string
+	// Size is the number of response body bytes written
+	Size    int
+	Referer string
+	// User is the basic auth user name, if any
+	User string
+}
+
+// LogRequest logs req at level in the access log format of GinMiddleware
+func (l *Logger) LogRequest(level LogLevel, req Request, fields ...Field) {
+	if !l.enabled(level) {
+		return
+	}
+	l.output(0, level, req.message(nil), fields)
+}
+
+// LogRequestFormat is like LogRequest, but builds the message with format,
+// e.g. CombinedLogFormat, unless it is nil
+func (l *Logger) LogRequestFormat(level LogLevel, req Request, format func(req Request) string, fields ...Field) {
+	if !l.enabled(level) {
+		return
+	}
+	msg := req.message(nil)
+	if format != nil {
+		msg = format(req)
+	}
+	l.output(0, level, msg, fields)
+}
+
+// RequestField selects a part of a request for GinFields
+type RequestField int
+
+const (
+	RequestMethod RequestField = iota
+	RequestPath
+	RequestStatus
+	RequestClientIP
+	RequestLatency
+	// RequestUserAgent is the client's OS and browser
+	RequestUserAgent
+	RequestErrors
+)
+
+var defaultRequestFields = []RequestField{RequestMethod, RequestPath, RequestStatus,
+	RequestClientIP, RequestLatency, RequestUserAgent, RequestErrors}
+
+// message returns the access log message shared by the HTTP middlewares,
+// with the given parts of the request or all of them if fields is empty
+func (r Request) message(fields []RequestField) string {
+	if len(fields) == 0 {
+		fields = defaultRequestFields
+	}
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		switch field {
+		case RequestMethod:
+			parts = append(parts, r.Method)
+		case RequestPath:
+			parts = append(parts, r.Path)
+		case RequestStatus:
+			parts = append(parts, strconv.Itoa(r.Status))
+		case RequestClientIP:
+			parts = append(parts, r.ClientIP)
+		case RequestLatency:
+			parts = append(parts, r.Latency.String())
+		case RequestUserAgent:
+			os, browser := parseUserAgent(r.UserAgent)
+			parts = append(parts, os+" "+browser)
+		case RequestErrors:
+			parts = append(parts, r.Errors)
+		}
+	}
+	return "Request: " + strings.Join(parts, " ")
+}
+
+// statusWriter records the status and body size written through a ResponseWriter
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
+}
+
+// Flush flushes the underlying ResponseWriter if it supports it
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack takes over the connection, as for WebSocket upgrades, if the
+// underlying ResponseWriter supports it. The request is logged with status
+// 101 unless the handler set one
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// ReadFrom copies r to the response, letting the underlying ResponseWriter
+// use sendfile where it can
+func (w *statusWriter) ReadFrom(r io.Reader) (int64, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	var n int64
+	var err error
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		n, err = io.Copy(w.ResponseWriter, r)
+	}
+	w.size += int(n)
+	return n, err
+}
+
+// Push initiates an HTTP/2 server push if the underlying ResponseWriter
+// supports it
+func (w *statusWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Unwrap returns the underlying ResponseWriter, for http.ResponseController
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Status returns the response status, 200 if the handler didn't set one
+func (w *statusWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// clientIP returns the client's address, taken like Gin's default from
+// X-Forwarded-For or X-Real-Ip before the connection's remote address
+func clientIP(r *http.Request) string {
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		ip, _, _ := strings.Cut(fwd, ",")
+		if ip = strings.TrimSpace(ip); ip != "" {
+			return ip
+		}
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-Ip")); ip != "" {
+		return ip
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
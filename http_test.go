@@ -0,0 +1,94 @@
+package simplelog
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPMiddlewareHijack(t *testing.T) {
+	l := NewWriter(INFO, io.Discard)
+	logged := make(chan string, 1)
+	l.AddHook(func(entry Entry) { logged <- entry.Message })
+	srv := httptest.NewServer(l.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, ok := w.(http.Hijacker)
+		if !ok {
+			t.Error("ResponseWriter doesn't implement http.Hijacker")
+			return
+		}
+		conn, rw, err := h.Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+	})))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/ws", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("got status %d, want 101", resp.StatusCode)
+	}
+	// The access log is written once the handler returns, which may be after
+	// the client has seen the response
+	select {
+	case got := <-logged:
+		if !strings.Contains(got, "GET /ws 101") {
+			t.Errorf("got %q, want the upgrade logged with status 101", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("upgrade not logged")
+	}
+}
+
+func TestStatusWriterReadFrom(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := &statusWriter{ResponseWriter: rec}
+	var _ io.ReaderFrom = w
+	n, err := w.ReadFrom(strings.NewReader("hello"))
+	if err != nil || n != 5 {
+		t.Fatalf("ReadFrom = %d, %v", n, err)
+	}
+	if w.Status() != http.StatusOK || w.size != 5 || rec.Body.String() != "hello" {
+		t.Errorf("got status %d size %d body %q", w.Status(), w.size, rec.Body.String())
+	}
+	if err := w.Push("/app.js", nil); err != http.ErrNotSupported {
+		t.Errorf("Push on a recorder = %v, want http.ErrNotSupported", err)
+	}
+	if _, _, err := w.Hijack(); err != http.ErrNotSupported {
+		t.Errorf("Hijack on a recorder = %v, want http.ErrNotSupported", err)
+	}
+}
+
+func TestHTTPMiddlewareCombinedFormat(t *testing.T) {
+	l := NewWriter(INFO, io.Discard)
+	var got string
+	l.AddHook(func(entry Entry) { got = entry.Message })
+	h := l.HTTPMiddlewareWith(HTTPFormat(CombinedLogFormat))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/x?a=1", nil)
+	req.SetBasicAuth("alice", "secret")
+	req.Header.Set("Referer", "https://example.com/")
+	req.Header.Set("User-Agent", "test-agent")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := regexp.MustCompile(`^192\.0\.2\.1 - alice \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /x\?a=1 HTTP/1\.1" 200 5 "https://example\.com/" "test-agent"$`)
+	if !want.MatchString(got) {
+		t.Errorf("got %q, want a complete combined log line", got)
+	}
+}
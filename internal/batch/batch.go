@@ -0,0 +1,136 @@
+// Package batch queues entries for the network sinks and sends them in
+// batches from a background goroutine
+package batch
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/base-go/simplelog"
+)
+
+// Config configures a Queue
+type Config struct {
+	// Name prefixes the errors WriteEntry returns, e.g. "slloki"
+	Name string
+	// QueueSize is how many entries may wait before new ones are dropped
+	QueueSize int
+	// BatchSize is the most entries sent at once
+	BatchSize int
+	// FlushInterval is how often a partial batch is sent
+	FlushInterval time.Duration
+	// Retry is how failed batches are retried before being dropped
+	Retry simplelog.RetryPolicy
+	// OnDrop, if set, is called with the error and the number of entries
+	// when a batch is dropped
+	OnDrop func(err error, n int)
+}
+
+// Queue batches entries and passes them to a send function. Entries that
+// can't be queued or delivered are dropped and counted
+type Queue struct {
+	cfg  Config
+	send func(seq uint64, entries []simplelog.Entry) error
+	seq  uint64
+
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan simplelog.Entry
+	done    chan struct{}
+	dropped atomic.Uint64
+}
+
+// New starts a Queue sending batches with send, which is retried according
+// to cfg.Retry and may mark errors simplelog.Permanent
+func New(cfg Config, send func(entries []simplelog.Entry) error) *Queue {
+	return NewSeq(cfg, func(_ uint64, entries []simplelog.Entry) error { return send(entries) })
+}
+
+// NewSeq is like New, but also passes send the sequence number of the batch,
+// counting from 1. Retries of a batch get the same number
+func NewSeq(cfg Config, send func(seq uint64, entries []simplelog.Entry) error) *Queue {
+	q := &Queue{
+		cfg:   cfg,
+		send:  send,
+		queue: make(chan simplelog.Entry, cfg.QueueSize),
+		done:  make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// WriteEntry queues entry for delivery without blocking
+func (q *Queue) WriteEntry(entry simplelog.Entry) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		q.dropped.Add(1)
+		return errors.New(q.cfg.Name + ": sink is closed")
+	}
+	select {
+	case q.queue <- entry:
+		return nil
+	default:
+		q.dropped.Add(1)
+		return errors.New(q.cfg.Name + ": queue is full")
+	}
+}
+
+// Dropped returns the number of entries that were not delivered
+func (q *Queue) Dropped() uint64 {
+	return q.dropped.Load()
+}
+
+// Close sends any queued entries and stops the background goroutine
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.queue)
+	}
+	q.mu.Unlock()
+
+	<-q.done
+	return nil
+}
+
+func (q *Queue) run() {
+	defer close(q.done)
+
+	ticker := time.NewTicker(q.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	var batch []simplelog.Entry
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		q.seq++
+		if err := q.cfg.Retry.Do(func() error { return q.send(q.seq, batch) }); err != nil {
+			q.dropped.Add(uint64(len(batch)))
+			if q.cfg.OnDrop != nil {
+				q.cfg.OnDrop(err, len(batch))
+			}
+		}
+		batch = nil
+	}
+
+	for {
+		select {
+		case entry, ok := <-q.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= q.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package batch
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/base-go/simplelog"
+)
+
+// Post sends body to url with the given headers, returning an error for any
+// status but 2xx. Client errors other than 408 and 429 are marked
+// simplelog.Permanent, as resending the same batch won't fix them
+func Post(client *http.Client, url string, header http.Header, body io.Reader) error {
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		return simplelog.Permanent(err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	err = fmt.Errorf("%s: %s", resp.Status, msg)
+	switch {
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests:
+		return err
+	case resp.StatusCode/100 == 4:
+		return simplelog.Permanent(err)
+	}
+	return err
+}
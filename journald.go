@@ -0,0 +1,156 @@
+//go:build linux
+
+package simplelog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// journalSocket is where systemd-journald listens for native protocol messages
+const journalSocket = "/run/systemd/journal/socket"
+
+// JournalSink is a Sink that writes entries to the systemd journal using its
+// native protocol, so the level, caller and fields become journal fields
+// (PRIORITY, CODE_FILE, CODE_LINE and one per field) instead of text
+type JournalSink struct {
+	conn       *net.UnixConn
+	addr       *net.UnixAddr
+	identifier string
+}
+
+// NewJournalSink connects to the local journal. identifier is recorded as
+// SYSLOG_IDENTIFIER; an empty identifier leaves it to journald
+func NewJournalSink(identifier string) (*JournalSink, error) {
+	if _, err := os.Stat(journalSocket); err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	addr := &net.UnixAddr{Name: journalSocket, Net: "unixgram"}
+	return &JournalSink{conn: conn, addr: addr, identifier: identifier}, nil
+}
+
+// WithJournal sends entries to the systemd journal as well, see
+// NewJournalSink. New returns an error if journald isn't running
+func WithJournal(identifier string) Option {
+	return func(c *config) {
+		c.sinks = append(c.sinks, func() (Sink, error) {
+			return NewJournalSink(identifier)
+		})
+	}
+}
+
+// WriteEntry sends entry to the journal
+func (s *JournalSink) WriteEntry(entry Entry) error {
+	var b bytes.Buffer
+	writeJournalField(&b, "MESSAGE", entry.Message)
+	writeJournalField(&b, "PRIORITY", strconv.Itoa(syslogSeverity(entry.Level)))
+	if entry.File != "" {
+		writeJournalField(&b, "CODE_FILE", entry.File)
+		writeJournalField(&b, "CODE_LINE", strconv.Itoa(entry.Line))
+	}
+	if entry.Function != "" {
+		writeJournalField(&b, "CODE_FUNC", entry.Function)
+	}
+	if entry.Logger != "" {
+		writeJournalField(&b, "LOGGER", entry.Logger)
+	}
+	if s.identifier != "" {
+		writeJournalField(&b, "SYSLOG_IDENTIFIER", s.identifier)
+	}
+	for _, field := range entry.Fields {
+		writeJournalField(&b, journalFieldName(field.Key), textValue(field.Value, QuoteNever))
+	}
+	if entry.Stack != "" {
+		writeJournalField(&b, "STACK", entry.Stack)
+	}
+
+	_, _, err := s.conn.WriteMsgUnix(b.Bytes(), nil, s.addr)
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		return s.writeLarge(b.Bytes())
+	}
+	return err
+}
+
+// writeLarge sends a message too big for a datagram the way journald expects:
+// written to an unlinked temporary file whose descriptor is passed instead
+func (s *JournalSink) writeLarge(msg []byte) error {
+	f, err := os.CreateTemp("/dev/shm", "simplelog-journal-")
+	if err != nil {
+		if f, err = os.CreateTemp("", "simplelog-journal-"); err != nil {
+			return err
+		}
+	}
+	defer f.Close()
+	if err := os.Remove(f.Name()); err != nil {
+		return err
+	}
+	if _, err := f.Write(msg); err != nil {
+		return err
+	}
+	rights := syscall.UnixRights(int(f.Fd()))
+	_, _, err = s.conn.WriteMsgUnix(nil, rights, s.addr)
+	return err
+}
+
+// Close closes the connection to the journal
+func (s *JournalSink) Close() error {
+	return s.conn.Close()
+}
+
+// writeJournalField appends one field in the native protocol encoding; values
+// containing a newline use the length-prefixed binary form
+func writeJournalField(b *bytes.Buffer, name, value string) {
+	b.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteByte('\n')
+	binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// journalReserved are the journal fields with a meaning to journald or its
+// tools, and those the sink writes itself. Field keys mapping to them get the
+// F_ prefix instead of overriding them
+var journalReserved = map[string]bool{
+	"MESSAGE": true, "MESSAGE_ID": true, "PRIORITY": true, "CODE_FILE": true,
+	"CODE_LINE": true, "CODE_FUNC": true, "ERRNO": true, "INVOCATION_ID": true,
+	"USER_INVOCATION_ID": true, "SYSLOG_FACILITY": true, "SYSLOG_IDENTIFIER": true,
+	"SYSLOG_PID": true, "SYSLOG_TIMESTAMP": true, "SYSLOG_RAW": true,
+	"DOCUMENTATION": true, "TID": true, "UNIT": true, "USER_UNIT": true,
+	"LOGGER": true, "STACK": true,
+}
+
+// journalFieldName converts a field key to a valid journal field name:
+// uppercase letters, digits and underscores, not starting with an underscore
+// or digit, at most 64 characters. Keys that would collide with a reserved
+// field are prefixed with F_
+func journalFieldName(key string) string {
+	name := []byte(strings.ToUpper(key))
+	for i, c := range name {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			name[i] = '_'
+		}
+	}
+	if len(name) == 0 || name[0] == '_' || name[0] >= '0' && name[0] <= '9' || journalReserved[string(name)] {
+		name = append([]byte("F_"), name...)
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return string(name)
+}
@@ -0,0 +1,31 @@
+//go:build linux
+
+package simplelog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJournalFieldName(t *testing.T) {
+	tests := map[string]string{
+		"user_id":               "USER_ID",
+		"request-id":            "REQUEST_ID",
+		"_private":              "F__PRIVATE",
+		"2fa":                   "F_2FA",
+		"":                      "F_",
+		"message":               "F_MESSAGE",
+		"Priority":              "F_PRIORITY",
+		"code_file":             "F_CODE_FILE",
+		"code.line":             "F_CODE_LINE",
+		"syslog_identifier":     "F_SYSLOG_IDENTIFIER",
+		"stack":                 "F_STACK",
+		"message_text":          "MESSAGE_TEXT",
+		strings.Repeat("a", 70): strings.Repeat("A", 64),
+	}
+	for key, want := range tests {
+		if got := journalFieldName(key); got != want {
+			t.Errorf("journalFieldName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
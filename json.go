@@ -0,0 +1,82 @@
+package simplelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JSONFormatter renders each entry as a single-line JSON object with time,
+// level, caller and message keys followed by the entry's fields, in the
+// entry's field order. Fields whose key collides with one of those keys are
+// written as "fields.<key>"
+type JSONFormatter struct {
+	// TimeFormat is the layout of the time key; it defaults to time.RFC3339Nano
+	TimeFormat string
+}
+
+var jsonReservedKeys = map[string]bool{"time": true, "level": true, "logger": true, "caller": true, "func": true, "message": true, "stack": true}
+
+func (f *JSONFormatter) Format(entry Entry) ([]byte, error) {
+	layout := f.TimeFormat
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	writeJSONField(&buf, "time", entry.Time.Format(layout))
+	buf.WriteByte(',')
+	writeJSONField(&buf, "level", strings.ToLower(levelToString(entry.Level)))
+	buf.WriteByte(',')
+	if entry.Logger != "" {
+		writeJSONField(&buf, "logger", entry.Logger)
+		buf.WriteByte(',')
+	}
+	if entry.File != "" {
+		writeJSONField(&buf, "caller", fmt.Sprintf("%s:%d", entry.File, entry.Line))
+		buf.WriteByte(',')
+	}
+	if entry.Function != "" {
+		writeJSONField(&buf, "func", entry.Function)
+		buf.WriteByte(',')
+	}
+	writeJSONField(&buf, "message", entry.Message)
+	for _, field := range entry.Fields {
+		key := field.Key
+		if jsonReservedKeys[key] {
+			key = "fields." + key
+		}
+		buf.WriteByte(',')
+		writeJSONField(&buf, key, field.Value)
+	}
+	if entry.Stack != "" {
+		buf.WriteByte(',')
+		writeJSONField(&buf, "stack", entry.Stack)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+// writeJSONField writes "key":value to buf
+func writeJSONField(buf *bytes.Buffer, key string, value interface{}) {
+	k, _ := json.Marshal(key)
+	buf.Write(k)
+	buf.WriteByte(':')
+	buf.Write(jsonValue(value))
+}
+
+// jsonValue encodes v, using the message of errors and the %v form of values
+// that can't be marshaled
+func jsonValue(v interface{}) []byte {
+	if err, ok := v.(error); ok {
+		v = err.Error()
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(v))
+	}
+	return b
+}
@@ -0,0 +1,91 @@
+package simplelog
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// builtinLevels are the levels simplelog defines, in order
+var builtinLevels = []LogLevel{TRACE, DEBUG, INFO, WARN, ERROR, PANIC, FATAL}
+
+var (
+	customLevelsMu sync.RWMutex
+	customLevels   = map[LogLevel]string{}
+)
+
+// RegisterLevel adds a custom level with the given severity and name, e.g.
+//
+//	var AUDIT = simplelog.RegisterLevel(35, "AUDIT")
+//
+// so that entries logged at it with Logger.Log are named and filtered like the
+// built-in levels. It panics if the severity or name is already taken, so call
+// it from a package-level variable or init
+func RegisterLevel(level LogLevel, name string) LogLevel {
+	customLevelsMu.Lock()
+	defer customLevelsMu.Unlock()
+
+	taken := map[LogLevel]string{NoLevel: "NoLevel"}
+	for _, l := range builtinLevels {
+		taken[l] = levelToString(l)
+	}
+	for l, n := range customLevels {
+		taken[l] = n
+	}
+	for l, n := range taken {
+		if l == level || n == name {
+			panic(fmt.Sprintf("simplelog: level %d %s is already registered", int(l), n))
+		}
+	}
+	customLevels[level] = name
+	return level
+}
+
+// customLevelName returns the name a custom level was registered with
+func customLevelName(level LogLevel) (string, bool) {
+	customLevelsMu.RLock()
+	defer customLevelsMu.RUnlock()
+
+	name, ok := customLevels[level]
+	return name, ok
+}
+
+// ParseLevel returns the level named s, ignoring case, e.g. "debug" or "WARN".
+// "warning" is accepted for WARN, and registered custom level names work too
+func ParseLevel(s string) (LogLevel, error) {
+	name := strings.ToUpper(strings.TrimSpace(s))
+	if name == "WARNING" {
+		return WARN, nil
+	}
+	for _, level := range builtinLevels {
+		if levelToString(level) == name {
+			return level, nil
+		}
+	}
+
+	customLevelsMu.RLock()
+	defer customLevelsMu.RUnlock()
+
+	for level, n := range customLevels {
+		if strings.ToUpper(n) == name {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("simplelog: unknown level %q", s)
+}
+
+// MarshalText returns the level's name, so levels can be written to config files
+func (l LogLevel) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText parses a level name with ParseLevel, so levels can be read
+// from config files and set with flag.TextVar
+func (l *LogLevel) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
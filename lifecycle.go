@@ -0,0 +1,63 @@
+package simplelog
+
+import "os"
+
+// SetLifecycleMarkers enables a "process started" entry, written before the
+// next entry is logged, and a "process stopping" entry written by Close.
+// Both carry the PID and hostname, along with the global fields such as the
+// version from SetVersion
+func (l *Logger) SetLifecycleMarkers(enabled bool) {
+	l.mu.Lock()
+	l.lifecycle = enabled
+	l.mu.Unlock()
+
+	l.startPending.Store(enabled)
+}
+
+// Close writes the stop marker if enabled, writes any queued entries and ends
+// async mode, flushes buffered entries and closes the log file and the sinks
+// opened by New. Entries logged afterwards go to the console and to sinks
+// added with AddSink only
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	lifecycle := l.lifecycle
+	l.mu.Unlock()
+	if lifecycle {
+		l.marker("process stopping")
+	}
+	defer l.compressWG.Wait()
+	l.asyncMu.Lock()
+	l.stopDropSummary()
+	l.asyncMu.Unlock()
+	l.SetAsync(0)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.flushStop != nil {
+		close(l.flushStop)
+		l.flushStop = nil
+	}
+	l.endDuplicateRunLocked()
+	err := l.flushLocked()
+	l.buf = nil
+	if l.file != nil {
+		if cerr := l.file.Close(); err == nil {
+			err = cerr
+		}
+		l.file = nil
+	}
+	l.fileFailed = false
+	l.rotateFailing = false
+	if cerr := l.closeSinks(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// marker writes a lifecycle entry, regardless of the logger's level
+func (l *Logger) marker(msg string) {
+	host, _ := os.Hostname()
+	fields := []Field{{Key: "pid", Value: os.Getpid()}, {Key: "hostname", Value: host}}
+	l.output(1, INFO, msg, fields)
+}
@@ -0,0 +1,76 @@
+package simplelog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogfmtFormatter renders entries as logfmt lines, e.g.
+//
+//	ts=2024-01-02T10:00:00Z level=info caller=main.go:12 msg="user logged in" user_id=42
+type LogfmtFormatter struct {
+	// TimeFormat is the layout of the ts key; it defaults to time.RFC3339Nano
+	TimeFormat string
+	// Quote controls when string values are quoted; QuoteNever can produce
+	// lines that logfmt parsers can't split
+	Quote QuoteMode
+}
+
+func (f *LogfmtFormatter) Format(entry Entry) ([]byte, error) {
+	layout := f.TimeFormat
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+
+	var b strings.Builder
+	b.WriteString("ts=")
+	b.WriteString(entry.Time.Format(layout))
+	b.WriteString(" level=")
+	b.WriteString(strings.ToLower(levelToString(entry.Level)))
+	if entry.Logger != "" {
+		f.writePair(&b, "logger", entry.Logger)
+	}
+	if entry.File != "" {
+		fmt.Fprintf(&b, " caller=%s:%d", entry.File, entry.Line)
+	}
+	if entry.Function != "" {
+		f.writePair(&b, "func", entry.Function)
+	}
+	f.writePair(&b, "msg", entry.Message)
+	for _, field := range entry.Fields {
+		f.writePair(&b, field.Key, field.Value)
+	}
+	if entry.Stack != "" {
+		f.writePair(&b, "stack", entry.Stack)
+	}
+	b.WriteByte('\n')
+	return []byte(b.String()), nil
+}
+
+func (f *LogfmtFormatter) writePair(b *strings.Builder, key string, value interface{}) {
+	b.WriteByte(' ')
+	b.WriteString(logfmtKey(key))
+	b.WriteByte('=')
+
+	s := textValue(value, QuoteNever)
+	switch {
+	case f.Quote == QuoteAlways, f.Quote != QuoteNever && needsQuote(s):
+		s = strconv.Quote(s)
+	}
+	b.WriteString(s)
+}
+
+// logfmtKey replaces the characters a logfmt key can't contain
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f {
+			return '_'
+		}
+		return r
+	}, key)
+}
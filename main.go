@@ -1,13 +1,22 @@
 package simplelog
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"runtime/debug"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -16,82 +25,568 @@ import (
 // LogLevel represents the severity of a log message
 type LogLevel int
 
+// The built-in levels are spaced apart so custom levels can be registered
+// between them, see RegisterLevel
 const (
-	DEBUG LogLevel = iota
-	INFO
-	WARN
-	ERROR
+	// TRACE is for very verbose diagnostics, below DEBUG
+	TRACE LogLevel = 5
+	DEBUG LogLevel = 10
+	INFO  LogLevel = 20
+	WARN  LogLevel = 30
+	ERROR LogLevel = 40
+	// PANIC entries are followed by a panic, see Logger.Panic
+	PANIC LogLevel = 50
+	// FATAL entries end the process, see Logger.Fatal
+	FATAL LogLevel = 60
 )
 
+// NoLevel is reported by HighestLevelSeen before any entry has been written;
+// it is lower than every level
+const NoLevel LogLevel = math.MinInt32
+
 // Logger is the main struct for the logging system
 type Logger struct {
-	level      LogLevel
-	output     io.Writer
+	*state
+	fields     []Field
+	callerSkip int
+	name       string
+	encoded    []encodedValue
+}
+
+// state holds the outputs and settings shared by a Logger and the loggers derived from it
+type state struct {
+	level      atomic.Int64
+	console    io.Writer
 	file       *os.File
+	fileName   string
+	maxSize    int64
+	openedAt   time.Time
+	timeRange  bool
+	period     RotationPeriod
+	rotateAt   time.Time
+	compress   Compression
+	compressWG sync.WaitGroup
+	maxBackups int
+	maxAge     time.Duration
+	naming     string
+	colorMode  ColorMode
+	color      bool
+	icons      bool
+	consoleTTY bool
+	stripANSI  bool
+	quote      QuoteMode
+	levelIcons map[LogLevel]string
 	mu         sync.Mutex
 	timeFormat string
+	formatter  Formatter
+	formatters map[LogLevel]Formatter
+	maxEntry   int
+	hooks      []Hook
+	sinks      []Sink
+	fieldOrder FieldOrder
+	fullStacks atomic.Bool
+	eventLevel atomic.Int64
+	global     []Field
+	exit       func(code int)
+	configPath string
+	callerMode atomic.Int32
+	callerFunc atomic.Bool
+	stacks     atomic.Bool
+	stackLevel atomic.Int64
+	extractors []ContextExtractor
+	named      atomic.Pointer[map[string]LogLevel]
+
+	// owned are the sinks New opened from options, closed by Close
+	owned []io.Closer
+
+	lifecycle    bool
+	startPending atomic.Bool
+
+	limiter     atomic.Pointer[rateLimiter]
+	rateExempt  atomic.Int64
+	rateDropped atomic.Uint64
+
+	sampler       atomic.Pointer[sampler]
+	sampleDropped atomic.Uint64
+
+	highest  LogLevel
+	lastTime time.Time
+	sequence bool
+	seq      uint64
+	fallback *Logger
+
+	outputs      []levelWriter
+	consoleLevel LogLevel
+	fileLevel    LogLevel
+
+	buf         *bufio.Writer
+	bufEntries  int
+	bufMax      int
+	bufInterval time.Duration
+	lastFlush   time.Time
+	flushStop   chan struct{}
+
+	asyncMu      sync.RWMutex
+	async        *asyncQueue
+	asyncBlocked atomic.Uint64
+	asyncDropped atomic.Uint64
+	overflow     AsyncOverflow
+	summaryStop  chan struct{}
+
+	dedup *dedupState
+
+	errHandler ErrorHandler
+	errPending atomic.Bool
+	errs       []error
+
+	failover    io.Writer
+	retryEvery  time.Duration
+	fileFailed  bool
+	fileRetryAt time.Time
+	sinkRetryAt []time.Time
+
+	// rotateFailing is set while rotations fail, see rotationFailed
+	rotateFailing bool
+
+	redact map[string]bool
+	masks  []Mask
+
+	paused       bool
+	pauseMode    PauseMode
+	pauseBuffer  []Entry
+	pauseLimit   int
+	pauseDropped uint64
+
+	onceMu    sync.Mutex
+	callSites map[string]struct{}
 }
 
-var (
-	logFile     string
-	maxFileSize int64 = 10 * 1024 * 1024 // 10MB
-)
+// TimeFormatNano is a time format with nanosecond precision. Entries are
+// timestamped under the logger's lock and timestamps never decrease, but
+// entries may still share one, e.g. when the clock steps back and they are
+// held at the last timestamp. Sorting by timestamp and then by the seq field
+// of SetSequence restores the order entries were written in
+const TimeFormatNano = "2006-01-02T15:04:05.000000000Z07:00"
+
+// defaultMaxFileSize is the size the log file is rotated at unless changed
+const defaultMaxFileSize = 10 * 1024 * 1024 // 10MB
+
+// New creates a new Logger instance configured by opts. Without options it
+// logs INFO and above to stdout only, with no file, e.g.
+//
+//	l, err := simplelog.New(simplelog.WithLevel(simplelog.DEBUG), simplelog.WithFile("app.log"))
+//
+// It returns an error if the log file can't be opened, unless
+// WithBestEffortFile is given
+func New(opts ...Option) (*Logger, error) {
+	cfg := config{level: INFO, stdout: true, timeFormat: DefaultTimeFormat}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	var file *os.File
+	var err error
+	if cfg.file != "" {
+		file, err = os.OpenFile(cfg.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil && !cfg.bestEffort {
+			return nil, fmt.Errorf("simplelog: opening log file: %w", err)
+		}
+	}
+
+	l := newLogger(cfg.level, file)
+	l.fileName = cfg.file
+	l.timeFormat = cfg.timeFormat
+	l.callerMode.Store(int32(cfg.callerMode))
+	if cfg.maxSize > 0 {
+		l.maxSize = cfg.maxSize
+	}
+	l.errHandler = cfg.errHandler
+	l.SetFormat(cfg.format)
+	switch {
+	case !cfg.stdout:
+		l.console = nil
+	case cfg.console != nil:
+		l.console = cfg.console
+		l.resolveConsole()
+	}
+	for _, open := range cfg.sinks {
+		sink, serr := open()
+		if serr != nil {
+			l.closeSinks()
+			if file != nil {
+				file.Close()
+			}
+			return nil, serr
+		}
+		l.sinks = append(l.sinks, sink)
+		if c, ok := sink.(io.Closer); ok {
+			l.owned = append(l.owned, c)
+		}
+	}
+	if err != nil {
+		l.log(WARN, "Could not open log file %s, continuing without it: %v", cfg.file, err)
+	}
+	return l, nil
+}
 
-// New creates a new Logger instance
-func New(level LogLevel, filename string) *Logger {
-	logFile = filename
-	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// MustNew is like New but panics if the logger can't be created
+func MustNew(opts ...Option) *Logger {
+	l, err := New(opts...)
 	if err != nil {
 		panic(err)
 	}
+	return l
+}
 
-	return &Logger{
-		level:      level,
-		output:     io.MultiWriter(os.Stdout, file),
-		file:       file,
-		timeFormat: "2006-01-02 15:04:05",
-	}
+// NewWriter creates a new Logger that formats entries and writes them to w
+// only, with no file, rotation or stdout. Rotation settings have no effect,
+// and Close does not close w
+func NewWriter(level LogLevel, w io.Writer) *Logger {
+	l := newLogger(level, nil)
+	l.console = w
+	l.resolveConsole()
+	return l
+}
+
+func newLogger(level LogLevel, file *os.File) *Logger {
+	l := &Logger{state: &state{
+		console:      os.Stdout,
+		file:         file,
+		maxSize:      defaultMaxFileSize,
+		openedAt:     time.Now(),
+		timeFormat:   DefaultTimeFormat,
+		highest:      NoLevel,
+		fileLevel:    NoLevel,
+		consoleLevel: NoLevel,
+		levelIcons:   defaultLevelIcons(),
+		compress:     CompressGzipAsync,
+		exit:         os.Exit,
+	}}
+	l.level.Store(int64(level))
+	l.eventLevel.Store(int64(INFO))
+	l.rateExempt.Store(int64(ERROR))
+	l.resolveConsole()
+	return l
+}
+
+// resolveConsole decides whether console output is colored and gets icons,
+// after the console writer or color mode changes. l.mu must be held unless l
+// is new
+func (l *Logger) resolveConsole() {
+	l.color = resolveColor(l.colorMode, l.console)
+	l.consoleTTY = isTerminal(l.console)
 }
 
 func (l *Logger) log(level LogLevel, format string, args ...interface{}) {
-	if level < l.level {
+	if !l.enabled(level) {
+		return
+	}
+	msg := format
+	if len(args) > 0 || strings.IndexByte(format, '%') >= 0 {
+		msg = fmt.Sprintf(format, args...)
+	}
+	l.output(1, level, msg, nil)
+}
+
+// output builds and writes an entry with the logger's fields followed by
+// fields. depth is the number of frames between output and the exported
+// method the user called
+func (l *Logger) output(depth int, level LogLevel, msg string, fields []Field) {
+	if !l.rateAllowed(level) || !l.sampleAllowed(level, msg, &fields) {
 		return
 	}
+	l.emit(l.newEntry(depth+1, level, msg, fields))
+}
+
+// newEntry builds an unwritten entry reporting the caller depth frames above
+// newEntry's caller
+func (l *Logger) newEntry(depth int, level LogLevel, msg string, fields []Field) Entry {
+	entry := Entry{
+		Level:   level,
+		Message: msg,
+		Fields:  l.fields,
+		Logger:  l.name,
+		encoded: l.encoded,
+	}
+	if mode := CallerMode(l.callerMode.Load()); mode != CallerNone {
+		pc, file, line, _ := runtime.Caller(2 + depth + l.callerSkip)
+		entry.File, entry.Line = callerFile(mode, file), line
+		if l.callerFunc.Load() {
+			entry.Function = funcName(pc)
+		}
+	}
+	for _, field := range fields {
+		if len(entry.encoded) > 0 && hasField(l.fields, field.Key) {
+			entry.encoded = nil
+		}
+		entry.Fields = withField(entry.Fields, field.Key, field.Value)
+	}
+	entry.Stack = errorStack(entry.Fields, l.fullStacks.Load())
+	if l.stacks.Load() && level >= LogLevel(l.stackLevel.Load()) {
+		if stack := callerStack(2 + depth + l.callerSkip); entry.Stack != "" {
+			entry.Stack += "\nlogged at:\n" + stack
+		} else {
+			entry.Stack = stack
+		}
+	}
+	return entry
+}
+
+// emit writes entry and fires the hooks, or queues it in async mode. It
+// returns the entry as written and whether it was written or queued
+func (l *Logger) emit(entry Entry) (Entry, bool) {
+	if l.startPending.CompareAndSwap(true, false) {
+		l.marker("process started")
+	}
+	if async, queued := l.enqueue(&entry); async {
+		return entry, queued
+	}
+	return l.deliver(entry)
+}
+
+// deliver writes entry and fires the hooks, see emit
+func (l *Logger) deliver(entry Entry) (Entry, bool) {
+	// Hooks run after the lock is released so they may log through l themselves
+	hooks, ok, fallback := l.write(&entry)
+	if fallback != nil {
+		fallback.writeVerbatim(entry)
+	}
+	if l.errPending.Load() {
+		l.reportErrors()
+	}
+	if ok {
+		for _, h := range hooks {
+			h(entry)
+		}
+	}
+	return entry, ok
+}
 
+// write timestamps, formats and writes entry under the lock. It returns the
+// hooks to fire, whether the entry was written, and the fallback logger to
+// hand it to if writing to an output failed
+func (l *Logger) write(entry *Entry) ([]Hook, bool, *Logger) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	// Check file size and rotate if necessary
-	if fi, err := l.file.Stat(); err == nil && fi.Size() > maxFileSize {
-		l.rotateLog()
+	// Read the clock once per entry, never going back past the previous entry
+	// if the wall clock is adjusted. Queued entries were timestamped when logged
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
 	}
+	if entry.Time.Before(l.lastTime) {
+		entry.Time = l.lastTime
+	}
+	l.lastTime = entry.Time
 
-	// Get caller information
-	_, file, line, _ := runtime.Caller(2)
+	if l.sequence {
+		l.seq++
+		if len(entry.encoded) > 0 && hasField(entry.Fields, SequenceKey) {
+			entry.encoded = nil
+		}
+		entry.Fields = withField(entry.Fields, SequenceKey, l.seq)
+	}
 
-	// Format the log message
-	msg := fmt.Sprintf(format, args...)
-	logEntry := fmt.Sprintf("[%s] %s %s:%d: %s\n",
-		time.Now().Format(l.timeFormat),
-		levelToString(level),
-		filepath.Base(file),
-		line,
-		msg)
+	if len(l.global) > 0 || l.fieldOrder == FieldOrderSorted || l.stripANSI {
+		entry.encoded = nil
+	}
+	if len(l.global) > 0 {
+		fields := l.global
+		for _, field := range entry.Fields {
+			fields = withField(fields, field.Key, field.Value)
+		}
+		entry.Fields = fields
+	}
+	if l.fieldOrder == FieldOrderSorted {
+		entry.Fields = sortedFields(entry.Fields)
+	}
+	if l.redact != nil {
+		l.redactEntry(entry)
+	}
+	if l.stripANSI {
+		stripEntryANSI(entry)
+	}
+	if len(l.masks) > 0 {
+		l.maskEntry(entry)
+	}
 
-	// Write to output
-	fmt.Fprint(l.output, logEntry)
+	if l.paused {
+		l.holdPaused(*entry)
+		return nil, false, nil
+	}
+	if l.suppressDuplicate(entry) {
+		return nil, false, nil
+	}
+	ok, err := l.writeLocked(entry)
+	if err != nil {
+		return l.hooks, ok, l.fallback
+	}
+	return l.hooks, ok, nil
 }
 
-func (l *Logger) rotateLog() {
-	l.file.Close()
-	os.Rename(logFile, logFile+"."+time.Now().Format("2006-01-02-15-04-05"))
-	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// writeLocked formats and writes entry to the outputs, reporting whether it
+// was written and the first error returned by an output. l.mu must be held
+func (l *Logger) writeLocked(entry *Entry) (bool, error) {
+	// Check file size and schedule and rotate if necessary, or try again to
+	// open the file if a rotation closed it but couldn't open its replacement
+	if l.file == nil && l.rotateFailing {
+		if err := l.openLog(entry.Time); err != nil {
+			l.rotationFailed(fmt.Errorf("simplelog: reopening log file: %w", err))
+		}
+	} else if l.file != nil {
+		if fi, err := l.file.Stat(); err == nil {
+			size := fi.Size()
+			if l.buf != nil {
+				size += int64(l.buf.Buffered())
+			}
+			due := l.period != RotateNever && !entry.Time.Before(l.rotateAt)
+			if size > l.maxSize || due && size > 0 {
+				if err := l.rotateLog(); err != nil {
+					l.rotationFailed(fmt.Errorf("simplelog: rotating log file: %w", err))
+				}
+			} else if due {
+				l.rotateAt = l.period.next(entry.Time)
+			}
+		}
+	}
 
+	// Format the log message into a pooled buffer, falling back to the text
+	// format if a custom formatter fails. Writers must not retain what they are given
+	buf := getBuffer()
+	defer putBuffer(buf)
+	f, custom := l.formatterFor(entry.Level)
+	logEntry, err := formatTo(buf, f, *entry)
 	if err != nil {
-		panic(err)
+		f, custom = l.textFormatter(), false
+		logEntry, _ = formatTo(buf, f, *entry)
+	}
+
+	// Enforce the size limit by truncating the message, dropping entries that can't fit
+	if l.maxEntry > 0 && len(logEntry) > l.maxEntry {
+		var ok bool
+		if *entry, logEntry, ok = fitEntry(f, *entry, logEntry, l.maxEntry); !ok {
+			l.pendError(fmt.Errorf("simplelog: entry exceeds %d bytes, dropped", l.maxEntry))
+			return false, nil
+		}
+	}
+
+	// Write to outputs; only the built-in text format is colored or gets
+	// icons, and never in the file
+	consoleEntry := logEntry
+	icons := l.icons && l.consoleTTY
+	if (l.color || icons) && !custom {
+		console := l.textFormatter()
+		console.color = l.color
+		if icons {
+			console.icon = l.levelIcons[entry.Level]
+		}
+		consoleBuf := getBuffer()
+		defer putBuffer(consoleBuf)
+		consoleEntry, _ = formatTo(consoleBuf, console, *entry)
+	}
+	if l.console != nil && entry.Level >= l.consoleLevel {
+		if _, err = l.console.Write(consoleEntry); err != nil {
+			l.pendError(fmt.Errorf("simplelog: writing to console: %w", err))
+		}
+	}
+	for _, o := range l.outputs {
+		if entry.Level < o.level {
+			continue
+		}
+		if _, werr := o.w.Write(logEntry); werr != nil {
+			l.pendError(fmt.Errorf("simplelog: writing to output: %w", werr))
+			if err == nil {
+				err = werr
+			}
+		}
+	}
+	if (l.file != nil || l.fileFailed) && entry.Level >= l.fileLevel {
+		if ferr := l.writeFileFailover(logEntry); ferr != nil {
+			l.pendError(fmt.Errorf("simplelog: writing log file: %w", ferr))
+			if err == nil {
+				err = ferr
+			}
+		}
+	}
+	for i, s := range l.sinks {
+		if serr := l.writeSinkFailover(i, s, *entry, logEntry); serr != nil {
+			l.pendError(fmt.Errorf("simplelog: writing to sink %T: %w", s, serr))
+		}
+	}
+	if entry.Level > l.highest {
+		l.highest = entry.Level
+	}
+	return true, err
+}
+
+// writeVerbatim writes an entry handed over by another logger as it is,
+// without restamping it or passing it on to a fallback of its own
+func (l *Logger) writeVerbatim(entry Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.paused {
+		l.holdPaused(entry)
+		return
+	}
+	l.writeLocked(&entry)
+}
+
+// rotationFailed passes err to the error handler or, without one, writes it
+// to stderr when rotation starts failing, rather than crashing. The logger
+// keeps going with the file as it is if only the rename failed, or without a
+// file until a later write, Rotate or Reopen manages to open it. l.mu must be held
+func (l *Logger) rotationFailed(err error) {
+	if l.errHandler != nil {
+		l.pendError(err)
+	} else if !l.rotateFailing {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	l.rotateFailing = true
+}
+
+// rotateLog renames the log file aside and starts a new one. l.mu must be held
+func (l *Logger) rotateLog() error {
+	l.flushLocked()
+	if l.file != nil {
+		l.file.Close()
+	}
+	now := time.Now()
+	rotated := l.rotatedName(now)
+	if os.Rename(l.fileName, rotated) == nil {
+		l.archive(rotated)
+	}
+	return l.openLog(now)
+}
+
+// openLog opens the log file for appending in place of the closed l.file.
+// l.mu must be held
+func (l *Logger) openLog(now time.Time) error {
+	file, err := os.OpenFile(l.fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		l.file = nil
+		return err
 	}
 	l.file = file
-	l.output = io.MultiWriter(os.Stdout, file)
+	l.fileFailed = false
+	l.rotateFailing = false
+	l.openedAt = now
+	l.rotateAt = l.period.next(now)
+	if l.buf != nil {
+		l.buf.Reset(file)
+	}
+	return nil
+}
+
+// Log logs a message at level, which may be a custom level, see RegisterLevel
+func (l *Logger) Log(level LogLevel, format string, args ...interface{}) {
+	l.log(level, format, args...)
+}
+
+// Trace logs a trace-level message. While TRACE is disabled it returns before
+// formatting anything, so it is cheap to leave in tight loops
+func (l *Logger) Trace(format string, args ...interface{}) {
+	l.log(TRACE, format, args...)
 }
 
 // Debug logs a debug-level message
@@ -114,8 +609,162 @@ func (l *Logger) Error(format string, args ...interface{}) {
 	l.log(ERROR, format, args...)
 }
 
+// Panic logs a panic-level message, flushes the outputs and panics with the
+// message. The panic happens even if PANIC is filtered out
+func (l *Logger) Panic(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if l.enabled(PANIC) {
+		l.output(0, PANIC, msg, nil)
+	}
+	l.Flush()
+	panic(msg)
+}
+
+// Fatal logs a fatal-level message, closes the logger so queued and buffered
+// entries are written, then exits the process with status 1, see SetExitFunc.
+// The exit happens even if FATAL is filtered out
+func (l *Logger) Fatal(format string, args ...interface{}) {
+	if l.enabled(FATAL) {
+		l.output(0, FATAL, fmt.Sprintf(format, args...), nil)
+	}
+	l.Close()
+
+	l.mu.Lock()
+	exit := l.exit
+	l.mu.Unlock()
+	exit(1)
+}
+
+// SetExitFunc sets the function Fatal calls to end the process, os.Exit by
+// default. A function that returns lets Fatal return too
+func (l *Logger) SetExitFunc(exit func(code int)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.exit = exit
+}
+
+// Dump pretty-prints v under label at debug level, one continuation line per
+// line of output. It is a no-op, and does no formatting, unless DEBUG is enabled
+func (l *Logger) Dump(label string, v interface{}) {
+	if !l.enabled(DEBUG) {
+		return
+	}
+	l.log(DEBUG, "%s:\n%s", label, dumpValue(v))
+}
+
+// dumpValue renders v as indented JSON, falling back to %+v for values that
+// can't be marshaled
+func dumpValue(v interface{}) string {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		out = []byte(fmt.Sprintf("%+v", v))
+	}
+	return "    " + strings.ReplaceAll(string(out), "\n", "\n    ")
+}
+
+// Event logs a structured event at the event level (INFO by default). The
+// entry's message is name, and it carries an event=name field placed after
+// the global fields and the logger's own fields, and before the given fields
+func (l *Logger) Event(name string, fields ...Field) {
+	level := LogLevel(l.eventLevel.Load())
+	if !l.enabled(level) {
+		return
+	}
+	l.output(0, level, name, append([]Field{{Key: "event", Value: name}}, fields...))
+}
+
+// SetEventLevel sets the level Event logs at
+func (l *Logger) SetEventLevel(level LogLevel) {
+	l.eventLevel.Store(int64(level))
+}
+
+// DebugOncef logs a debug-level message only the first time its call site executes
+func (l *Logger) DebugOncef(format string, args ...interface{}) {
+	if l.enabled(DEBUG) && l.firstAtCallSite() {
+		l.log(DEBUG, format, args...)
+	}
+}
+
+// InfoOncef logs an info-level message only the first time its call site executes
+func (l *Logger) InfoOncef(format string, args ...interface{}) {
+	if l.enabled(INFO) && l.firstAtCallSite() {
+		l.log(INFO, format, args...)
+	}
+}
+
+// WarnOncef logs a warn-level message only the first time its call site executes
+func (l *Logger) WarnOncef(format string, args ...interface{}) {
+	if l.enabled(WARN) && l.firstAtCallSite() {
+		l.log(WARN, format, args...)
+	}
+}
+
+// ErrorOncef logs an error-level message only the first time its call site executes
+func (l *Logger) ErrorOncef(format string, args ...interface{}) {
+	if l.enabled(ERROR) && l.firstAtCallSite() {
+		l.log(ERROR, format, args...)
+	}
+}
+
+func (l *Logger) enabled(level LogLevel) bool {
+	if l.name != "" {
+		if min, ok := l.namedLevel(); ok {
+			return level >= min
+		}
+	}
+	return level >= LogLevel(l.level.Load())
+}
+
+// SetLevel sets the minimum level logged. It is safe to call while other
+// goroutines are logging, e.g. to turn on DEBUG in a running service
+func (l *Logger) SetLevel(level LogLevel) {
+	l.level.Store(int64(level))
+}
+
+// Enabled reports whether entries at level are logged, taking named levels
+// into account, so that callers can skip preparing entries that would be
+// dropped
+func (l *Logger) Enabled(level LogLevel) bool {
+	return l.enabled(level)
+}
+
+// GetLevel returns the minimum level logged
+func (l *Logger) GetLevel() LogLevel {
+	return LogLevel(l.level.Load())
+}
+
+// firstAtCallSite reports whether this is the first time the calling *Oncef
+// method has been reached from its caller's file:line
+func (l *Logger) firstAtCallSite() bool {
+	_, file, line, ok := runtime.Caller(2 + l.callerSkip)
+	if !ok {
+		return true
+	}
+	key := fmt.Sprintf("%s:%d", file, line)
+
+	l.onceMu.Lock()
+	defer l.onceMu.Unlock()
+
+	if _, seen := l.callSites[key]; seen {
+		return false
+	}
+	if l.callSites == nil {
+		l.callSites = make(map[string]struct{})
+	}
+	l.callSites[key] = struct{}{}
+	return true
+}
+
+// String returns the level's name, e.g. "INFO"
+func (l LogLevel) String() string {
+	return levelToString(l)
+}
+
 func levelToString(level LogLevel) string {
 	switch level {
+	case TRACE:
+		return "TRACE"
 	case DEBUG:
 		return "DEBUG"
 	case INFO:
@@ -124,14 +773,83 @@ func levelToString(level LogLevel) string {
 		return "WARN"
 	case ERROR:
 		return "ERROR"
+	case PANIC:
+		return "PANIC"
+	case FATAL:
+		return "FATAL"
 	default:
+		if name, ok := customLevelName(level); ok {
+			return name
+		}
 		return "UNKNOWN"
 	}
 }
 
 // SetMaxFileSize sets the maximum size of the log file before rotation
 func (l *Logger) SetMaxFileSize(size int64) {
-	maxFileSize = size
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.maxSize = size
+}
+
+// RotationPeriod is a schedule for rotating the log file, in addition to the
+// size limit
+type RotationPeriod int
+
+const (
+	// RotateNever rotates on size only
+	RotateNever RotationPeriod = iota
+	// RotateHourly rotates at the start of every hour
+	RotateHourly
+	// RotateDaily rotates at local midnight
+	RotateDaily
+)
+
+// next returns the first rotation time after t
+func (p RotationPeriod) next(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch p {
+	case RotateHourly:
+		return time.Date(y, m, d, t.Hour()+1, 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+// SetRotationPeriod rotates the log file on a schedule as well as on size.
+// Rotation happens with the first entry written after the boundary, and a file
+// that is still empty is not rotated
+func (l *Logger) SetRotationPeriod(period RotationPeriod) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.period = period
+	l.rotateAt = period.next(l.openedAt)
+}
+
+// SetRotationTimeRange makes rotated file names include both the time the file
+// was opened and the time it was rotated, instead of just the rotation time
+func (l *Logger) SetRotationTimeRange(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.timeRange = enabled
+}
+
+// SequenceKey is the field numbering entries, see SetSequence
+const SequenceKey = "seq"
+
+// SetSequence numbers entries in the order they are written, from 1, in a
+// seq field. Together with TimeFormatNano it gives entries a total order
+// that survives sorting
+func (l *Logger) SetSequence(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sequence = enabled
 }
 
 // SetTimeFormat sets the time format used in log entries
@@ -139,33 +857,762 @@ func (l *Logger) SetTimeFormat(format string) {
 	l.timeFormat = format
 }
 
+// formatterFor returns the formatter for level and whether it is a custom one
+func (l *Logger) formatterFor(level LogLevel) (Formatter, bool) {
+	if f, ok := l.formatters[level]; ok {
+		return f, true
+	}
+	if l.formatter != nil {
+		return l.formatter, true
+	}
+	return l.textFormatter(), false
+}
+
+// textFormatter returns the built-in text formatter with the logger's settings
+func (l *Logger) textFormatter() TextFormatter {
+	return TextFormatter{TimeFormat: l.timeFormat, Quote: l.quote}
+}
+
+// EntrySize returns the number of bytes entry would occupy once formatted
+// with the formatter configured for its level, without writing it
+func (l *Logger) EntrySize(entry Entry) (int, error) {
+	l.mu.Lock()
+	f, _ := l.formatterFor(entry.Level)
+	l.mu.Unlock()
+
+	b, err := f.Format(entry)
+	return len(b), err
+}
+
+// SetMaxEntrySize limits the formatted size of an entry in bytes. Oversized
+// entries have their message truncated to fit, and are dropped with an error
+// passed to the error handler if even an empty message would exceed the
+// limit. A size of 0 disables the limit
+func (l *Logger) SetMaxEntrySize(size int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.maxEntry = size
+}
+
+// SetColorMode overrides color detection for console output.
+// An explicit ColorAlways or ColorNever takes precedence over the NO_COLOR,
+// FORCE_COLOR and CLICOLOR_FORCE environment variables, which in turn take
+// precedence over TTY detection
+func (l *Logger) SetColorMode(mode ColorMode) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.colorMode = mode
+	l.resolveConsole()
+}
+
+// With returns a logger that adds fields to every entry it logs, given as
+// alternating keys and values or as Field values, e.g.
+//
+//	reqLog := l.With("order_id", id, "tenant", tenant)
+//
+// The fields' text form is encoded once here rather than for every entry, so
+// values are captured as they are now. The returned logger shares its outputs
+// and settings with l
+func (l *Logger) With(keyvals ...interface{}) *Logger {
+	c := l.clone()
+	for _, field := range keyvalFields(keyvals) {
+		c.fields = withField(c.fields, field.Key, field.Value)
+	}
+	c.encoded = encodeFields(c.fields)
+	return c
+}
+
+// WithFields returns a logger that adds fields to every entry it logs, e.g.
+//
+//	l.WithFields(map[string]interface{}{"user_id": 42}).Info("login")
+//
+// Map order is random, so the fields are added sorted by key. The returned
+// logger shares its outputs and settings with l
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	c := l.clone()
+	for _, k := range keys {
+		c.fields = withField(c.fields, k, fields[k])
+	}
+	c.encoded = encodeFields(c.fields)
+	return c
+}
+
+// SetGlobalField adds key=value to every entry written by l and the loggers
+// sharing its outputs, ahead of their own fields, in every format and sink.
+// A nil value removes the field
+func (l *Logger) SetGlobalField(key string, value interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if value != nil {
+		l.global = withField(l.global, key, value)
+		return
+	}
+	var kept []Field
+	for _, field := range l.global {
+		if field.Key != key {
+			kept = append(kept, field)
+		}
+	}
+	l.global = kept
+}
+
+// SetVersion sets the build version, e.g. from an -ldflags variable, that is
+// added to every entry as the global field version. An empty version removes it
+func (l *Logger) SetVersion(version string) {
+	if version == "" {
+		l.SetGlobalField("version", nil)
+		return
+	}
+	l.SetGlobalField("version", version)
+}
+
+// CallerMode selects how the caller of each entry is reported
+type CallerMode int
+
+const (
+	// CallerShort reports the file name and line, e.g. main.go:12
+	CallerShort CallerMode = iota
+	// CallerFull reports the full path of the file and the line
+	CallerFull
+	// CallerNone doesn't look up the caller, which saves its cost
+	CallerNone
+)
+
+// SetCallerMode sets how the caller of each entry is reported, CallerShort by
+// default
+func (l *Logger) SetCallerMode(mode CallerMode) {
+	l.callerMode.Store(int32(mode))
+}
+
+// SetCallerFunction adds the calling function, e.g. main.(*Server).handle,
+// to the caller of each entry
+func (l *Logger) SetCallerFunction(enabled bool) {
+	l.callerFunc.Store(enabled)
+}
+
+// callerFile returns file as mode reports it
+func callerFile(mode CallerMode, file string) string {
+	if mode == CallerFull {
+		return file
+	}
+	return filepath.Base(file)
+}
+
+// funcName returns the name of the function containing pc without its
+// package path, e.g. main.(*Server).handle
+func funcName(pc uintptr) string {
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+	name := fn.Name()
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// WithCallerSkip returns a logger that skips n additional stack frames when
+// reporting the caller, so wrapper packages can report their own caller.
+// The returned logger shares its outputs and settings with l
+func (l *Logger) WithCallerSkip(n int) *Logger {
+	c := l.clone()
+	c.callerSkip += n
+	return c
+}
+
+func (l *Logger) clone() *Logger {
+	c := *l
+	return &c
+}
+
+// SetFullErrorStacks controls whether all frames of each wrapped error's stack
+// trace are logged. By default frames repeated across the error chain are collapsed
+func (l *Logger) SetFullErrorStacks(full bool) {
+	l.fullStacks.Store(full)
+}
+
+// SetFieldOrder sets the order in which structured fields are written
+func (l *Logger) SetFieldOrder(order FieldOrder) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.fieldOrder = order
+}
+
+// levelWriter is an additional output with its own minimum level
+type levelWriter struct {
+	w     io.Writer
+	level LogLevel
+}
+
+// AddOutput attaches w as an additional destination for formatted entries.
+// It is written the same bytes as the log file, without console colors
+func (l *Logger) AddOutput(w io.Writer) {
+	l.AddOutputLevel(w, NoLevel)
+}
+
+// AddOutputLevel attaches w as an additional destination that only receives
+// entries at level or above
+func (l *Logger) AddOutputLevel(w io.Writer, level LogLevel) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.outputs = append(l.outputs, levelWriter{w: w, level: level})
+}
+
+// SetOutputLevel sets the minimum level written to w, which may be an output
+// added with AddOutput or the console writer (stdout by default). The
+// logger's own level still applies first, so to get e.g. DEBUG in the file
+// but only WARN and above on stdout, set the logger to DEBUG and stdout to WARN
+func (l *Logger) SetOutputLevel(w io.Writer, level LogLevel) {
+	if w == nil || !reflect.TypeOf(w).Comparable() {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.console == w {
+		l.consoleLevel = level
+	}
+	for i := range l.outputs {
+		if l.outputs[i].w == w {
+			l.outputs[i].level = level
+		}
+	}
+}
+
+// SetFileLevel sets the minimum level written to the log file
+func (l *Logger) SetFileLevel(level LogLevel) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.fileLevel = level
+}
+
+// RemoveOutput detaches w, which may be an output added with AddOutput or the
+// console writer (stdout by default). Writers are matched by ==, so pass the
+// same value that was attached
+func (l *Logger) RemoveOutput(w io.Writer) {
+	// Comparing interfaces holding the same uncomparable type panics
+	if w == nil || !reflect.TypeOf(w).Comparable() {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.console == w {
+		l.console = nil
+		return
+	}
+	for i, o := range l.outputs {
+		if o.w == w {
+			l.outputs = append(l.outputs[:i:i], l.outputs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Sink receives every written entry in structured form, for outputs such as
+// remote services that do their own encoding and delivery
+type Sink interface {
+	WriteEntry(entry Entry) error
+}
+
+// AddSink registers a sink that receives every entry written by the logger.
+// WriteEntry is called under the logger's lock, so it should not block
+func (l *Logger) AddSink(s Sink) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sinks = append(l.sinks, s)
+}
+
+// SetFallback sets a logger that receives, unchanged, any entry that l failed
+// to write to its console or file, e.g. one writing to stderr or a file on
+// another disk. The fallback never passes entries on to a fallback of its
+// own, so chains and cycles of fallbacks can't recurse. nil removes it
+func (l *Logger) SetFallback(fallback *Logger) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.fallback = fallback
+}
+
+// HighestLevelSeen returns the highest level of any entry written since the
+// logger was created or ResetHighestLevel was last called, or NoLevel if
+// there were none. Tests can use it to check that a run logged no warnings:
+//
+//	if l.HighestLevelSeen() >= simplelog.WARN { t.Fatal("unexpected warnings") }
+func (l *Logger) HighestLevelSeen() LogLevel {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.highest
+}
+
+// ResetHighestLevel forgets previously written entries for HighestLevelSeen,
+// e.g. between subtests
+func (l *Logger) ResetHighestLevel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.highest = NoLevel
+}
+
+// closeSinks closes the sinks New opened from options and stops writing to
+// them, returning the first error. Sinks added with AddSink belong to the
+// caller and are left open
+func (l *Logger) closeSinks() error {
+	var err error
+	for _, c := range l.owned {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	kept := l.sinks[:0]
+	var retryAt []time.Time
+	for i, s := range l.sinks {
+		if c, ok := s.(io.Closer); ok && slices.Contains(l.owned, c) {
+			continue
+		}
+		kept = append(kept, s)
+		if i < len(l.sinkRetryAt) {
+			retryAt = append(retryAt, l.sinkRetryAt[i])
+		}
+	}
+	clear(l.sinks[len(kept):])
+	l.sinks = kept
+	l.sinkRetryAt = retryAt
+	l.owned = nil
+	return err
+}
+
+// Hook is a function called with each entry written by a Logger
+type Hook func(entry Entry)
+
+// AddHook registers a hook that is called with every entry after it has been
+// written. Hooks run outside the logger's lock, so a hook may log through the
+// same logger (e.g. to report its own failure) without deadlocking; it must
+// not do so unconditionally, as that entry would fire the hook again
+func (l *Logger) AddHook(h Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks = append(l.hooks, h)
+}
+
+// AddLevelHook registers a hook that is only called with entries at one of
+// levels, e.g. to page on ERROR or count WARN entries. It runs like the hooks
+// added by AddHook
+func (l *Logger) AddLevelHook(h Hook, levels ...LogLevel) {
+	want := make(map[LogLevel]bool, len(levels))
+	for _, level := range levels {
+		want[level] = true
+	}
+	l.AddHook(func(entry Entry) {
+		if want[entry.Level] {
+			h(entry)
+		}
+	})
+}
+
+// SetFormatter sets the formatter used for entries without a per-level
+// formatter, replacing the built-in text format; nil restores it. Console
+// colors and icons only apply to the built-in text format
+func (l *Logger) SetFormatter(f Formatter) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.formatter = f
+}
+
+// SetFormat selects the built-in format used for entries without a per-level formatter
+func (l *Logger) SetFormat(format Format) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch format {
+	case FormatGELF:
+		l.formatter = &GELFFormatter{Host: hostname()}
+	case FormatJSON:
+		l.formatter = &JSONFormatter{}
+	case FormatLogfmt:
+		l.formatter = &LogfmtFormatter{Quote: l.quote}
+	case FormatGCP:
+		l.formatter = &GCPFormatter{ProjectID: os.Getenv("GOOGLE_CLOUD_PROJECT")}
+	case FormatDatadog:
+		l.formatter = newDatadogFormatter()
+	case FormatRFC5424:
+		l.formatter = &RFC5424Formatter{}
+	default:
+		l.formatter = nil
+	}
+}
+
+// SetStripANSI removes ANSI escape sequences, e.g. from captured subprocess
+// output, from messages and string field values before they are written, so
+// that files and structured formats stay clean. The logger's own console
+// coloring is unaffected
+func (l *Logger) SetStripANSI(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stripANSI = enabled
+}
+
+// stripEntryANSI removes ANSI escape sequences from entry's message and string
+// fields. Fields are copied before changing them as they may be shared
+func stripEntryANSI(entry *Entry) {
+	entry.Message = stripANSI(entry.Message)
+
+	copied := false
+	for i, field := range entry.Fields {
+		s, ok := field.Value.(string)
+		if !ok || stripANSI(s) == s {
+			continue
+		}
+		if !copied {
+			entry.Fields = append([]Field(nil), entry.Fields...)
+			copied = true
+		}
+		entry.Fields[i].Value = stripANSI(s)
+	}
+}
+
+// SetQuoteStrings sets when string field values are quoted by the text format
+func (l *Logger) SetQuoteStrings(mode QuoteMode) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.quote = mode
+}
+
+// SetLevelIcons enables a leading per-level icon on console lines using the
+// built-in text format, when the console is a terminal. Icons are never
+// written to the file, redirected output or used by other formats
+func (l *Logger) SetLevelIcons(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.icons = enabled
+}
+
+// SetLevelIcon sets the console icon for level, replacing the default
+func (l *Logger) SetLevelIcon(level LogLevel, icon string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.levelIcons[level] = icon
+}
+
+// SetLevelFormatter sets the formatter used for entries at the given level.
+// Levels without a formatter use the default text format; passing nil removes the override
+func (l *Logger) SetLevelFormatter(level LogLevel, f Formatter) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if f == nil {
+		delete(l.formatters, level)
+		return
+	}
+	if l.formatters == nil {
+		l.formatters = make(map[LogLevel]Formatter)
+	}
+	l.formatters[level] = f
+}
+
+// GinOption configures the middleware returned by GinMiddleware
+type GinOption func(*ginConfig)
+
+type ginConfig struct {
+	statusLevel func(status int) LogLevel
+	handlerName bool
+	skipPaths   map[string]bool
+	skipPrefix  []string
+	format      func(req Request) string
+	fields      []RequestField
+	bodies      *bodyConfig
+	requestID   bool
+	slow        time.Duration
+	slowLevel   LogLevel
+}
+
+// skip reports whether requests for path aren't logged
+func (cfg *ginConfig) skip(path string) bool {
+	if cfg.skipPaths[path] {
+		return true
+	}
+	for _, prefix := range cfg.skipPrefix {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// GinSkipPaths stops requests for exactly these paths, e.g. "/metrics" and
+// "/healthz", from being logged
+func GinSkipPaths(paths ...string) GinOption {
+	return func(cfg *ginConfig) {
+		if cfg.skipPaths == nil {
+			cfg.skipPaths = make(map[string]bool)
+		}
+		for _, path := range paths {
+			cfg.skipPaths[path] = true
+		}
+	}
+}
+
+// GinSkipPrefixes stops requests for paths starting with any of prefixes from
+// being logged
+func GinSkipPrefixes(prefixes ...string) GinOption {
+	return func(cfg *ginConfig) {
+		cfg.skipPrefix = append(cfg.skipPrefix, prefixes...)
+	}
+}
+
+// GinSlowRequests logs requests taking longer than threshold at level, e.g.
+// WARN, unless their status calls for a higher one. Slow entries also carry
+// slow=true, the threshold, the handler name and the response size
+func GinSlowRequests(threshold time.Duration, level LogLevel) GinOption {
+	return func(cfg *ginConfig) {
+		cfg.slow = threshold
+		cfg.slowLevel = level
+	}
+}
+
+// GinFormat sets the function building the message for each request in
+// place of the default "Request: ..." line
+func GinFormat(fn func(req Request) string) GinOption {
+	return func(cfg *ginConfig) {
+		cfg.format = fn
+	}
+}
+
+// GinFields sets which parts of the request the default message includes,
+// in order. The default is all of them
+func GinFields(fields ...RequestField) GinOption {
+	return func(cfg *ginConfig) {
+		cfg.fields = fields
+	}
+}
+
+// GinStatusLevel sets the function choosing the level a request is logged at
+// from its response status. The default logs 5xx at ERROR, 4xx at WARN and
+// everything else at INFO
+func GinStatusLevel(fn func(status int) LogLevel) GinOption {
+	return func(cfg *ginConfig) {
+		cfg.statusLevel = fn
+	}
+}
+
+// GinHandlerName adds a handler field naming the handler function that served
+// the request. It is omitted for requests that matched no route
+func GinHandlerName() GinOption {
+	return func(cfg *ginConfig) {
+		cfg.handlerName = true
+	}
+}
+
+// DefaultStatusLevel maps 5xx statuses to ERROR, 4xx to WARN and the rest to INFO
+func DefaultStatusLevel(status int) LogLevel {
+	switch {
+	case status >= 500:
+		return ERROR
+	case status >= 400:
+		return WARN
+	default:
+		return INFO
+	}
+}
+
 // GinMiddleware returns a Gin middleware function for logging HTTP requests
-func (l *Logger) GinMiddleware() gin.HandlerFunc {
+func (l *Logger) GinMiddleware(opts ...GinOption) gin.HandlerFunc {
+	cfg := ginConfig{statusLevel: DefaultStatusLevel}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
 		raw := c.Request.URL.RawQuery
 
+		var id string
+		if cfg.requestID {
+			id = l.assignRequestID(c)
+		}
+
+		var reqBody string
+		var respBody *bodyWriter
+		if cfg.bodies != nil && !cfg.skip(path) {
+			reqBody = cfg.bodies.captureRequest(c)
+			respBody = &bodyWriter{ResponseWriter: c.Writer, limit: cfg.bodies.limit}
+			c.Writer = respBody
+		}
+
 		c.Next()
 
+		if cfg.skip(path) {
+			return
+		}
+
 		latency := time.Since(start)
 		if raw != "" {
 			path = path + "?" + raw
 		}
 
-		ua := c.Request.UserAgent()
-		os, browser := parseUserAgent(ua)
+		level := cfg.statusLevel(c.Writer.Status())
+		slow := cfg.slow > 0 && latency > cfg.slow
+		if slow && cfg.slowLevel > level {
+			level = cfg.slowLevel
+		}
+		if !l.enabled(level) {
+			return
+		}
+
+		var fields []Field
+		if id != "" {
+			fields = append(fields, Field{Key: "request_id", Value: id})
+		}
+		if (cfg.handlerName || slow) && c.FullPath() != "" {
+			fields = append(fields, Field{Key: "handler", Value: c.HandlerName()})
+		}
+		if slow {
+			fields = append(fields,
+				Field{Key: "slow", Value: true},
+				Field{Key: "threshold", Value: cfg.slow.String()},
+				Field{Key: "response_size", Value: c.Writer.Size()},
+			)
+		}
+		if reqBody != "" {
+			fields = append(fields, Field{Key: "request_body", Value: reqBody})
+		}
+		if respBody != nil && respBody.buf.Len() > 0 && cfg.bodies.logged(respBody.Header().Get("Content-Type")) {
+			fields = append(fields, Field{Key: "response_body", Value: cfg.bodies.text(respBody.buf.Bytes())})
+		}
+
+		req := Request{
+			Method:    c.Request.Method,
+			Path:      path,
+			Status:    c.Writer.Status(),
+			ClientIP:  c.ClientIP(),
+			Latency:   latency,
+			UserAgent: c.Request.UserAgent(),
+			Errors:    c.Errors.String(),
+			Time:      start,
+			Proto:     c.Request.Proto,
+			Size:      max(c.Writer.Size(), 0),
+			Referer:   c.Request.Referer(),
+		}
+		req.User, _, _ = c.Request.BasicAuth()
+		msg := req.message(cfg.fields)
+		if cfg.format != nil {
+			msg = cfg.format(req)
+		}
+		// Fields the context extractors find, such as trace IDs, come
+		// from the request's context
+		l.Ctx(c.Request.Context()).output(0, level, msg, fields)
+	}
+}
+
+// RecoveryOption configures the middleware returned by GinRecovery
+type RecoveryOption func(*recoveryConfig)
+
+type recoveryConfig struct {
+	level LogLevel
+	sink  Sink
+}
+
+// RecoveryLevel sets the level panics are logged at. The default is ERROR
+func RecoveryLevel(level LogLevel) RecoveryOption {
+	return func(cfg *recoveryConfig) {
+		cfg.level = level
+	}
+}
+
+// RecoverySink sends panic entries to sink as well as to the logger's own
+// outputs, e.g. a dedicated file that on-call can find crashes in
+func RecoverySink(sink Sink) RecoveryOption {
+	return func(cfg *recoveryConfig) {
+		cfg.sink = sink
+	}
+}
+
+// GinRecovery returns a Gin middleware that recovers from panics in later
+// handlers, logs them with their stack trace and the request's method, path
+// and request ID, and responds with 500. The entry always goes to the
+// logger's outputs, and additionally to the RecoverySink if one is set
+func (l *Logger) GinRecovery(opts ...RecoveryOption) gin.HandlerFunc {
+	cfg := recoveryConfig{level: ERROR}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(c *gin.Context) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+
+			fields := []Field{
+				{Key: "method", Value: c.Request.Method},
+				{Key: "path", Value: c.Request.URL.Path},
+			}
+			if id := c.GetHeader("X-Request-ID"); id != "" {
+				fields = append(fields, Field{Key: "request_id", Value: id})
+			}
+			entry := l.newEntry(0, cfg.level, fmt.Sprintf("Panic recovered: %v", r), fields)
+			entry.Stack = strings.TrimSuffix(string(debug.Stack()), "\n")
+			if file, line, ok := panicSite(); ok {
+				entry.File, entry.Line = callerFile(CallerMode(l.callerMode.Load()), file), line
+			}
+
+			written, ok := l.emit(entry)
+			if cfg.sink != nil {
+				if !ok {
+					written.Time = time.Now()
+				}
+				cfg.sink.WriteEntry(written)
+			}
 
-		l.log(INFO, "Request: %s %s %d %s %s %s %s %s",
-			c.Request.Method,
-			path,
-			c.Writer.Status(),
-			c.ClientIP(),
-			latency.String(),
-			os,
-			browser,
-			c.Errors.String(),
-		)
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}()
+
+		c.Next()
+	}
+}
+
+// panicSite returns the location of the panic being recovered, which is the
+// frame just above runtime.gopanic on the stack
+func panicSite() (file string, line int, ok bool) {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.gopanic" {
+			if next, _ := frames.Next(); next.File != "" {
+				return next.File, next.Line, true
+			}
+			return "", 0, false
+		}
+		if !more {
+			return "", 0, false
+		}
 	}
 }
 
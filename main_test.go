@@ -0,0 +1,192 @@
+package simplelog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaxEntrySizeDropReportsError(t *testing.T) {
+	var out bytes.Buffer
+	l := NewWriter(INFO, &out)
+	l.SetMaxEntrySize(100)
+	var errs []error
+	l.SetErrorHandler(func(err error) { errs = append(errs, err) })
+
+	l.Info("short")
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	l.With("payload", strings.Repeat("x", 200)).Info("too big")
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "exceeds 100 bytes") {
+		t.Fatalf("got errors %v, want one size error", errs)
+	}
+	if strings.Contains(out.String(), "too big") {
+		t.Errorf("oversized entry was written: %q", out.String())
+	}
+}
+
+func TestHookLogsThroughLogger(t *testing.T) {
+	var out bytes.Buffer
+	l := NewWriter(INFO, &out)
+	l.AddHook(func(entry Entry) {
+		if entry.Level >= ERROR {
+			l.Warn("forwarding failed for %q", entry.Message)
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Error("disk full")
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("logging from a hook deadlocked")
+	}
+
+	got := out.String()
+	if !strings.Contains(got, "disk full") || !strings.Contains(got, `forwarding failed for "disk full"`) {
+		t.Errorf("got %q, want the entry followed by the hook's entry", got)
+	}
+}
+
+func TestTimestampsNeverDecrease(t *testing.T) {
+	l := NewWriter(INFO, io.Discard)
+	l.SetSequence(true)
+	var entries []Entry
+	l.AddHook(func(entry Entry) { entries = append(entries, entry) })
+
+	for i := 0; i < 10000; i++ {
+		l.Info("tick")
+		if i == 5000 {
+			// Step the clock back: later entries are held at this timestamp
+			l.mu.Lock()
+			l.lastTime = time.Now().Add(time.Hour)
+			l.mu.Unlock()
+		}
+	}
+
+	for i := 1; i < len(entries); i++ {
+		prev, cur := entries[i-1], entries[i]
+		if cur.Time.Before(prev.Time) {
+			t.Fatalf("entry %d at %v is before entry %d at %v", i, cur.Time, i-1, prev.Time)
+		}
+		if cur.Fields[0].Value.(uint64) != prev.Fields[0].Value.(uint64)+1 {
+			t.Fatalf("entry %d has seq %v after %v", i, cur.Fields[0].Value, prev.Fields[0].Value)
+		}
+	}
+	if !entries[len(entries)-1].Time.Equal(entries[5001].Time) {
+		t.Error("entries after the clock stepped back weren't held at the last timestamp")
+	}
+}
+
+// failingWriter fails every write while failing is set
+type failingWriter struct {
+	failing bool
+	buf     bytes.Buffer
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.failing {
+		return 0, errors.New("disk full")
+	}
+	return w.buf.Write(p)
+}
+
+func TestFallbackReceivesFailedEntries(t *testing.T) {
+	primary := &failingWriter{}
+	l := NewWriter(INFO, io.Discard)
+	l.AddOutput(primary)
+	var fallback bytes.Buffer
+	l.SetFallback(NewWriter(INFO, &fallback))
+
+	l.Info("written")
+	primary.failing = true
+	l.With("user", "ann").Error("lost write")
+
+	if got := primary.buf.String(); !strings.Contains(got, "written") || strings.Contains(got, "lost write") {
+		t.Errorf("primary got %q", got)
+	}
+	got := fallback.String()
+	if strings.Contains(got, "written") {
+		t.Errorf("fallback got an entry the primary wrote: %q", got)
+	}
+	if !strings.Contains(got, "ERROR") || !strings.Contains(got, "lost write user=ann") {
+		t.Errorf("fallback got %q, want the failed entry", got)
+	}
+}
+
+func TestFailedRotationDoesNotPanic(t *testing.T) {
+	for _, handler := range []bool{false, true} {
+		dir := t.TempDir()
+		l, err := New(WithFile(filepath.Join(dir, "app.log")), WithStdout(false), WithMaxFileSize(10))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var errs []error
+		if handler {
+			l.SetErrorHandler(func(err error) { errs = append(errs, err) })
+		}
+		l.Info("fills the file past its maximum size")
+		// Replace the directory with a file, so the log file can neither be
+		// renamed aside nor reopened
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(dir, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		l.Info("rotates")
+		l.Info("still logging")
+		if handler && (len(errs) == 0 || !strings.Contains(errs[0].Error(), "rotating log file")) {
+			t.Errorf("got errors %v, want a rotation error", errs)
+		}
+
+		// Once the directory is back the file is opened again, by the next
+		// write or by Reopen
+		if err := os.Remove(dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if handler {
+			if err := l.Reopen(); err != nil {
+				t.Fatalf("Reopen after the failure cleared: %v", err)
+			}
+		}
+		l.Info("recovered")
+		b, err := os.ReadFile(filepath.Join(dir, "app.log"))
+		if err != nil || !strings.Contains(string(b), "recovered") {
+			t.Errorf("entry after the failure cleared not in the file: %q, %v", b, err)
+		}
+		l.Close()
+	}
+}
+
+func BenchmarkCallerMode(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		mode CallerMode
+	}{
+		{"short", CallerShort},
+		{"none", CallerNone},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			l := NewWriter(INFO, io.Discard)
+			l.SetCallerMode(bc.mode)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				l.Info("user logged in")
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package simplelog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Mask replaces text matching Pattern with Replacement in messages, stacks
+// and the text of string, error and fmt.Stringer field values, see SetMasks
+type Mask struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+
+	// valid, if set, filters matches, e.g. card numbers failing the Luhn check
+	valid func(s string) bool
+}
+
+// Built-in masks for common personal data. They favor catching the usual
+// forms over rejecting every look-alike; add custom masks for anything else
+var (
+	// MaskEmails masks email addresses
+	MaskEmails = Mask{
+		Pattern:     regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+		Replacement: "[EMAIL]",
+	}
+	// MaskCardNumbers masks 13 to 19 digit card numbers, optionally grouped
+	// with spaces or dashes, that pass the Luhn check
+	MaskCardNumbers = Mask{
+		Pattern:     regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+		Replacement: "[CARD]",
+		valid:       luhn,
+	}
+	// MaskPhoneNumbers masks phone numbers written in groups, such as
+	// +1 555-123-4567 or (555) 123 4567
+	MaskPhoneNumbers = Mask{
+		Pattern:     regexp.MustCompile(`(?:\+\d{1,3}[ .\-]?)?(?:\(\d{3}\) ?|\b\d{3}[ .\-])\d{3}[ .\-]\d{4}\b`),
+		Replacement: "[PHONE]",
+	}
+)
+
+// SetMasks sets the masks applied, in order, to messages, stacks and string,
+// error and fmt.Stringer field values before entries are written, e.g.
+//
+//	l.SetMasks(simplelog.MaskEmails, simplelog.MaskCardNumbers, simplelog.MaskPhoneNumbers)
+//
+// No masks turns masking off
+func (l *Logger) SetMasks(masks ...Mask) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.masks = append([]Mask(nil), masks...)
+}
+
+// AddMask adds a mask replacing matches of the regular expression pattern
+// with replacement, after those already set
+func (l *Logger) AddMask(pattern, replacement string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.masks = append(l.masks, Mask{Pattern: re, Replacement: replacement})
+	return nil
+}
+
+// apply returns s with m's matches replaced
+func (m Mask) apply(s string) string {
+	if m.valid == nil {
+		return m.Pattern.ReplaceAllString(s, m.Replacement)
+	}
+	return m.Pattern.ReplaceAllStringFunc(s, func(match string) string {
+		if !m.valid(match) {
+			return match
+		}
+		return m.Replacement
+	})
+}
+
+// maskText returns s with every mask applied
+func maskText(masks []Mask, s string) string {
+	for _, m := range masks {
+		s = m.apply(s)
+	}
+	return s
+}
+
+// maskEntry masks entry's message, stack and the text of its string, error
+// and Stringer fields, which are replaced by the masked text when it
+// changes. Fields are copied before changing them as they may be shared.
+// l.mu must be held
+func (l *Logger) maskEntry(entry *Entry) {
+	entry.Message = maskText(l.masks, entry.Message)
+	entry.Stack = maskText(l.masks, entry.Stack)
+
+	copied := false
+	for i, field := range entry.Fields {
+		v := valueText(field.Value)
+		if !v.quotable {
+			continue
+		}
+		masked := maskText(l.masks, v.s)
+		if masked == v.s {
+			continue
+		}
+		if !copied {
+			entry.Fields = append([]Field(nil), entry.Fields...)
+			entry.encoded = nil
+			copied = true
+		}
+		entry.Fields[i].Value = masked
+	}
+}
+
+// luhn reports whether the digits in s pass the Luhn checksum
+func luhn(s string) bool {
+	s = strings.NewReplacer(" ", "", "-", "").Replace(s)
+	sum := 0
+	for i := range s {
+		d := int(s[len(s)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
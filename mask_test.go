@@ -0,0 +1,41 @@
+package simplelog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMasksApplyToRenderedValues(t *testing.T) {
+	var out bytes.Buffer
+	l := NewWriter(INFO, &out)
+	l.SetMasks(MaskEmails)
+
+	err := errors.New("user ann@example.com not found")
+	l.With("err", err, "who", stringer("carol@example.com"), "note", "dave@example.com", "count", 3).
+		Error("lookup failed for bob@example.com")
+
+	got := out.String()
+	for _, addr := range []string{"ann@", "bob@", "carol@", "dave@"} {
+		if strings.Contains(got, addr) {
+			t.Errorf("%s leaked into %q", addr, got)
+		}
+	}
+	if !strings.Contains(got, "count=3") {
+		t.Errorf("unmasked field changed: %q", got)
+	}
+	if err.Error() != "user ann@example.com not found" {
+		t.Error("the logged error itself was changed")
+	}
+}
+
+func TestMasksApplyToStack(t *testing.T) {
+	entry := Entry{Message: "boom", Stack: "main.handle(ann@example.com)\n\tmain.go:12"}
+	l := NewWriter(INFO, &bytes.Buffer{})
+	l.SetMasks(MaskEmails)
+	l.maskEntry(&entry)
+	if strings.Contains(entry.Stack, "ann@") {
+		t.Errorf("stack not masked: %q", entry.Stack)
+	}
+}
@@ -0,0 +1,70 @@
+package simplelog
+
+import "strings"
+
+// Named returns a logger whose entries carry name, appended to l's own name
+// with a dot, e.g. l.Named("db").Named("pool") logs as "db.pool". Its level
+// can be set apart from l's with SetNamedLevel. The returned logger shares its
+// outputs and settings with l
+func (l *Logger) Named(name string) *Logger {
+	c := l.clone()
+	if c.name == "" {
+		c.name = name
+	} else {
+		c.name += "." + name
+	}
+	return c
+}
+
+// SetNamedLevel sets the minimum level of the loggers named name and the
+// loggers below it, e.g. "db" covers "db.pool", overriding SetLevel for them.
+// The most specific name wins
+func (l *Logger) SetNamedLevel(name string, level LogLevel) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	levels := make(map[string]LogLevel)
+	if old := l.named.Load(); old != nil {
+		for k, v := range *old {
+			levels[k] = v
+		}
+	}
+	levels[name] = level
+	l.named.Store(&levels)
+}
+
+// RemoveNamedLevel removes a level set with SetNamedLevel
+func (l *Logger) RemoveNamedLevel(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	old := l.named.Load()
+	if old == nil {
+		return
+	}
+	levels := make(map[string]LogLevel)
+	for k, v := range *old {
+		if k != name {
+			levels[k] = v
+		}
+	}
+	l.named.Store(&levels)
+}
+
+// namedLevel returns the level set for l's name or the nearest name above it
+func (l *Logger) namedLevel() (LogLevel, bool) {
+	levels := l.named.Load()
+	if levels == nil || len(*levels) == 0 {
+		return 0, false
+	}
+	for name := l.name; ; {
+		if level, ok := (*levels)[name]; ok {
+			return level, true
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return 0, false
+		}
+		name = name[:i]
+	}
+}
@@ -0,0 +1,217 @@
+package simplelog
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultRotatedName is the rotated file naming used unless changed,
+	// e.g. app.log.2024-01-02-10-00-00
+	defaultRotatedName = "{name}.{time:2006-01-02-15-04-05}"
+	// rangeRotatedName is the naming used by SetRotationTimeRange,
+	// e.g. app.2024-01-02T100000_2024-01-02T110000.log
+	rangeRotatedName = "{stem}.{opened:2006-01-02T150405}_{time:2006-01-02T150405}{ext}"
+)
+
+// SetRotatedNamePattern sets how rotated log files are named. The pattern is
+// a file name, or a path relative to the log file's directory, containing
+// these placeholders, shown for the log file app.log:
+//
+//	{name}           the log file's name, app.log
+//	{stem}           the name without its extension, app
+//	{ext}            the extension, .log
+//	{time}           the rotation time, 2006-01-02-15-04-05
+//	{time:LAYOUT}    the rotation time in a time.Format layout
+//	{opened}         the time the file was opened, in the same forms
+//	{n}              a sequence number
+//
+// With {n} and no time placeholder files are numbered like logrotate: the
+// newest is 1 and older files are renamed up one number on each rotation.
+// With a time placeholder as well, {n} is the lowest number not yet taken.
+// For example "{name}.{n}" gives app.log.1, and with daily rotation
+// "{stem}-{opened:20060102}{ext}" gives app-20240101.log (app-20240101.log.gz
+// when compressed). A pattern takes precedence over SetRotationTimeRange; an
+// empty pattern restores the default naming
+func (l *Logger) SetRotatedNamePattern(pattern string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.naming = pattern
+}
+
+// rotatedPatterns returns the naming pattern in effect, followed by the
+// built-in ones so retention still finds files named before a change
+func (l *Logger) rotatedPatterns() []string {
+	switch {
+	case l.naming != "":
+		return []string{l.naming}
+	case l.timeRange:
+		return []string{rangeRotatedName, defaultRotatedName}
+	default:
+		return []string{defaultRotatedName, rangeRotatedName}
+	}
+}
+
+// rotatedName returns the name the current log file is renamed to when
+// rotated at now, renumbering older files first if the pattern calls for it.
+// l.mu must be held
+func (l *Logger) rotatedName(now time.Time) string {
+	pattern := l.rotatedPatterns()[0]
+	name := func(n int) string {
+		return expandPattern(pattern, l.fileName, l.openedAt, now, n)
+	}
+	os.MkdirAll(filepath.Dir(name(0)), 0755)
+	if !strings.Contains(pattern, "{n}") {
+		return name(0)
+	}
+
+	if hasTimePlaceholder(pattern) {
+		n := 1
+		for exists(name(n)) || exists(name(n)+".gz") {
+			n++
+		}
+		return name(n)
+	}
+
+	// Renaming must not race a background compression of one of the files
+	l.compressWG.Wait()
+	last := 1
+	for exists(name(last)) || exists(name(last)+".gz") {
+		last++
+	}
+	for n := last - 1; n >= 1; n-- {
+		os.Rename(name(n), name(n+1))
+		os.Rename(name(n)+".gz", name(n+1)+".gz")
+	}
+	return name(1)
+}
+
+func exists(name string) bool {
+	_, err := os.Lstat(name)
+	return err == nil
+}
+
+func hasTimePlaceholder(pattern string) bool {
+	return strings.Contains(pattern, "{time") || strings.Contains(pattern, "{opened")
+}
+
+// expandPattern returns the path of a rotated file of logFile named by pattern
+func expandPattern(pattern, logFile string, opened, now time.Time, n int) string {
+	base := filepath.Base(logFile)
+	ext := filepath.Ext(base)
+	dir, file := patternDir(pattern, logFile)
+	name := replacePlaceholders(file, identity, func(key, layout string) string {
+		switch key {
+		case "name":
+			return base
+		case "stem":
+			return strings.TrimSuffix(base, ext)
+		case "ext":
+			return ext
+		case "time":
+			return now.Format(layout)
+		case "opened":
+			return opened.Format(layout)
+		default: // "n"
+			return strconv.Itoa(n)
+		}
+	})
+	return filepath.Join(dir, name)
+}
+
+// patternRegexp returns the directory the rotated files of logFile named by
+// pattern are in, and a regexp matching their names, compressed or not
+func patternRegexp(pattern, logFile string) (string, *regexp.Regexp) {
+	base := filepath.Base(logFile)
+	ext := filepath.Ext(base)
+	dir, file := patternDir(pattern, logFile)
+	expr := replacePlaceholders(file, regexp.QuoteMeta, func(key, layout string) string {
+		switch key {
+		case "name":
+			return regexp.QuoteMeta(base)
+		case "stem":
+			return regexp.QuoteMeta(strings.TrimSuffix(base, ext))
+		case "ext":
+			return regexp.QuoteMeta(ext)
+		case "time", "opened":
+			return layoutRegexp(layout)
+		default: // "n"
+			return `\d+`
+		}
+	})
+	return dir, regexp.MustCompile("^" + expr + `(\.gz)?$`)
+}
+
+// patternDir splits pattern into the directory rotated files are kept in and
+// the file name part, which is the only part placeholders are expanded in
+func patternDir(pattern, logFile string) (string, string) {
+	dir, file := filepath.Split(pattern)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(logFile), dir)
+	}
+	return filepath.Clean(dir), file
+}
+
+func identity(s string) string { return s }
+
+// replacePlaceholders rewrites s, passing the text between placeholders
+// through literal and replacing each placeholder with value(key, layout).
+// Unknown placeholders are kept as text
+func replacePlaceholders(s string, literal func(string) string, value func(key, layout string) string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(s, '{')
+		end := strings.IndexByte(s[max(start, 0):], '}') + max(start, 0)
+		if start < 0 || end < start {
+			b.WriteString(literal(s))
+			return b.String()
+		}
+		key, layout, _ := strings.Cut(s[start+1:end], ":")
+		switch key {
+		case "name", "stem", "ext", "n", "time", "opened":
+			if layout == "" {
+				layout = "2006-01-02-15-04-05"
+			}
+			b.WriteString(literal(s[:start]))
+			b.WriteString(value(key, layout))
+		default:
+			b.WriteString(literal(s[:end+1]))
+		}
+		s = s[end+1:]
+	}
+}
+
+// layoutSample is formatted to find which parts of a layout are digits
+var layoutSample = time.Date(2006, 11, 22, 13, 44, 55, 123456789, time.FixedZone("ABC", 5*3600+1800))
+
+// layoutRegexp returns a regexp matching times formatted with layout: runs of
+// digits and of letters in a formatted time are matched as such, and the rest
+// literally
+func layoutRegexp(layout string) string {
+	sample := layoutSample.Format(layout)
+	var b strings.Builder
+	for i := 0; i < len(sample); {
+		j := i + 1
+		switch c := sample[i]; {
+		case c >= '0' && c <= '9':
+			for j < len(sample) && sample[j] >= '0' && sample[j] <= '9' {
+				j++
+			}
+			b.WriteString(`\d+`)
+		case c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z':
+			for j < len(sample) && (sample[j] >= 'A' && sample[j] <= 'Z' || sample[j] >= 'a' && sample[j] <= 'z') {
+				j++
+			}
+			b.WriteString(`[A-Za-z]+`)
+		default:
+			b.WriteString(regexp.QuoteMeta(sample[i:j]))
+		}
+		i = j
+	}
+	return b.String()
+}
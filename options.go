@@ -0,0 +1,88 @@
+package simplelog
+
+import "io"
+
+// Option configures a Logger created by New
+type Option func(*config)
+
+type config struct {
+	level      LogLevel
+	file       string
+	bestEffort bool
+	stdout     bool
+	console    io.Writer
+	timeFormat string
+	sinks      []func() (Sink, error)
+	format     Format
+	callerMode CallerMode
+	maxSize    int64
+	errHandler ErrorHandler
+}
+
+// WithLevel sets the minimum level logged. The default is INFO
+func WithLevel(level LogLevel) Option {
+	return func(c *config) {
+		c.level = level
+	}
+}
+
+// WithFile writes entries to the named file as well, appending to it and
+// rotating it once it grows past the maximum file size
+func WithFile(filename string) Option {
+	return func(c *config) {
+		c.file = filename
+	}
+}
+
+// WithBestEffortFile makes a log file that can't be opened non-fatal: the
+// logger writes a single warning and continues without the file
+func WithBestEffortFile() Option {
+	return func(c *config) {
+		c.bestEffort = true
+	}
+}
+
+// WithStdout sets whether entries are written to stdout. The default is true
+func WithStdout(enabled bool) Option {
+	return func(c *config) {
+		c.stdout = enabled
+	}
+}
+
+// WithConsole writes console output to w, e.g. os.Stderr, instead of stdout.
+// Without WithFile this makes a console-only logger for containers and other
+// environments that collect logs from a process's output streams
+func WithConsole(w io.Writer) Option {
+	return func(c *config) {
+		c.console = w
+	}
+}
+
+// WithTimeFormat sets the time format used by the text format
+func WithTimeFormat(format string) Option {
+	return func(c *config) {
+		c.timeFormat = format
+	}
+}
+
+// WithFormat selects one of the built-in output formats, see SetFormat
+func WithFormat(format Format) Option {
+	return func(c *config) {
+		c.format = format
+	}
+}
+
+// WithCallerMode sets how the caller of each entry is reported, see
+// SetCallerMode. CallerNone skips the caller lookup on every entry
+func WithCallerMode(mode CallerMode) Option {
+	return func(c *config) {
+		c.callerMode = mode
+	}
+}
+
+// WithMaxFileSize sets the size the log file is rotated at, see SetMaxFileSize
+func WithMaxFileSize(size int64) Option {
+	return func(c *config) {
+		c.maxSize = size
+	}
+}
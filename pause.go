@@ -0,0 +1,73 @@
+package simplelog
+
+// PauseMode controls what happens to entries logged while a logger is paused
+type PauseMode int
+
+const (
+	// PauseDrop discards entries logged while paused and counts them
+	PauseDrop PauseMode = iota
+	// PauseBuffer holds entries logged while paused, up to a limit, and
+	// writes them on Resume. Entries beyond the limit are dropped and counted
+	PauseBuffer
+)
+
+// SetPauseMode sets how entries are handled while the logger is paused.
+// bufferSize bounds the number of entries held in PauseBuffer mode
+func (l *Logger) SetPauseMode(mode PauseMode, bufferSize int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pauseMode = mode
+	l.pauseLimit = bufferSize
+}
+
+// Pause suspends all output until Resume is called, without changing the level
+func (l *Logger) Pause() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.paused = true
+}
+
+// Resume ends a pause, first writing any entries buffered while paused
+func (l *Logger) Resume() {
+	l.mu.Lock()
+	l.paused = false
+	held := l.pauseBuffer
+	l.pauseBuffer = nil
+
+	var written []Entry
+	for i := range held {
+		if ok, _ := l.writeLocked(&held[i]); ok {
+			written = append(written, held[i])
+		}
+	}
+	hooks := l.hooks
+	l.mu.Unlock()
+
+	if l.errPending.Load() {
+		l.reportErrors()
+	}
+	for _, entry := range written {
+		for _, h := range hooks {
+			h(entry)
+		}
+	}
+}
+
+// PausedDropped returns the number of entries discarded while paused
+func (l *Logger) PausedDropped() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.pauseDropped
+}
+
+// holdPaused buffers or drops an entry logged while paused. l.mu must be held
+func (l *Logger) holdPaused(entry Entry) {
+	if l.pauseMode == PauseBuffer && len(l.pauseBuffer) < l.pauseLimit {
+		l.pauseBuffer = append(l.pauseBuffer, entry)
+		return
+	}
+	l.pauseDropped++
+}
@@ -0,0 +1,38 @@
+package simplelog
+
+import "sync"
+
+// maxPooledBuffer is the capacity above which buffers aren't returned to the
+// pool, so that one huge entry doesn't pin its memory
+const maxPooledBuffer = 64 << 10
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
+
+// getBuffer returns an empty buffer from the pool
+func getBuffer() *[]byte {
+	return bufferPool.Get().(*[]byte)
+}
+
+// putBuffer returns b to the pool. b must not be used afterwards
+func putBuffer(b *[]byte) {
+	if cap(*b) > maxPooledBuffer {
+		return
+	}
+	*b = (*b)[:0]
+	bufferPool.Put(b)
+}
+
+// formatTo renders entry with f. The built-in text format is appended to buf,
+// whose contents are only valid until the buffer is returned to the pool
+func formatTo(buf *[]byte, f Formatter, entry Entry) ([]byte, error) {
+	if tf, ok := f.(TextFormatter); ok {
+		*buf = tf.appendFormat((*buf)[:0], entry)
+		return *buf, nil
+	}
+	return f.Format(entry)
+}
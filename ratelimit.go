@@ -0,0 +1,75 @@
+package simplelog
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket holding up to one second's worth of tokens
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(perSecond int) *rateLimiter {
+	return &rateLimiter{
+		rate:   float64(perSecond),
+		tokens: float64(perSecond),
+		last:   time.Now(),
+	}
+}
+
+func (r *rateLimiter) allow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.tokens += now.Sub(r.last).Seconds() * r.rate
+	if r.tokens > r.rate {
+		r.tokens = r.rate
+	}
+	r.last = now
+
+	if r.tokens < 1 {
+		return false
+	}
+	r.tokens--
+	return true
+}
+
+// SetRateLimit caps the logger's output at perSecond entries per second,
+// allowing bursts of up to one second's worth. Entries over the limit are
+// dropped and counted, except those at or above the exempt level (ERROR by
+// default). A limit of 0 removes the cap
+func (l *Logger) SetRateLimit(perSecond int) {
+	if perSecond <= 0 {
+		l.limiter.Store(nil)
+		return
+	}
+	l.limiter.Store(newRateLimiter(perSecond))
+}
+
+// SetRateLimitExemptLevel sets the lowest level that bypasses the rate limit
+func (l *Logger) SetRateLimitExemptLevel(level LogLevel) {
+	l.rateExempt.Store(int64(level))
+}
+
+// RateLimited returns the number of entries dropped by the rate limit
+func (l *Logger) RateLimited() uint64 {
+	return l.rateDropped.Load()
+}
+
+// rateAllowed reports whether an entry at level is within the rate limit
+func (l *Logger) rateAllowed(level LogLevel) bool {
+	r := l.limiter.Load()
+	if r == nil || level >= LogLevel(l.rateExempt.Load()) {
+		return true
+	}
+	if r.allow() {
+		return true
+	}
+	l.rateDropped.Add(1)
+	return false
+}
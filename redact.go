@@ -0,0 +1,41 @@
+package simplelog
+
+import "strings"
+
+// Redacted replaces the values of fields named with SetRedactedKeys
+const Redacted = "[REDACTED]"
+
+// SetRedactedKeys sets the field names, matched case-insensitively, whose
+// values are replaced with Redacted before entries are written, e.g.
+// "password", "token" and "authorization". Global fields and those bound with
+// With are covered too. No keys turns redaction off
+func (l *Logger) SetRedactedKeys(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(keys) == 0 {
+		l.redact = nil
+		return
+	}
+	l.redact = make(map[string]bool, len(keys))
+	for _, key := range keys {
+		l.redact[strings.ToLower(key)] = true
+	}
+}
+
+// redactEntry replaces the values of entry's redacted fields. Fields are
+// copied before changing them as they may be shared. l.mu must be held
+func (l *Logger) redactEntry(entry *Entry) {
+	copied := false
+	for i, field := range entry.Fields {
+		if !l.redact[strings.ToLower(field.Key)] || field.Value == Redacted {
+			continue
+		}
+		if !copied {
+			entry.Fields = append([]Field(nil), entry.Fields...)
+			entry.encoded = nil
+			copied = true
+		}
+		entry.Fields[i].Value = Redacted
+	}
+}
@@ -0,0 +1,64 @@
+package simplelog
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// errNoFile is returned by Rotate and Reopen for loggers without a log file
+var errNoFile = errors.New("simplelog: logger has no log file")
+
+// Rotate rotates the log file now, as if it had reached its size limit
+func (l *Logger) Rotate() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.fileName == "" {
+		return errNoFile
+	}
+	return l.rotateLog()
+}
+
+// Reopen closes the log file and opens it again by name, for use after an
+// external tool such as logrotate has moved it aside. It also opens the file
+// again after a failed rotation or Close
+func (l *Logger) Reopen() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.fileName == "" {
+		return errNoFile
+	}
+	l.flushLocked()
+	if l.file != nil {
+		l.file.Close()
+	}
+	return l.openLog(time.Now())
+}
+
+// ReopenOnSignal reopens the log file whenever one of sigs is received,
+// typically syscall.SIGHUP sent from a logrotate postrotate script. Calling
+// the returned function stops handling the signals
+func (l *Logger) ReopenOnSignal(sigs ...os.Signal) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				if err := l.Reopen(); err != nil {
+					l.Error("Could not reopen log file: %v", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
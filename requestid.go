@@ -0,0 +1,70 @@
+package simplelog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader is the header GinRequestID reads and sets
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestID bounds the length of request IDs accepted from clients
+const maxRequestID = 128
+
+type requestIDKey struct{}
+
+// GinRequestID gives every request an ID, taken from its X-Request-ID header
+// or generated if it has none, and sets it on the response. The access log
+// entry carries it as request_id, and so do entries logged during the
+// request with the logger FromContext(c.Request.Context()) returns
+func GinRequestID() GinOption {
+	return func(cfg *ginConfig) {
+		cfg.requestID = true
+	}
+}
+
+// RequestID returns the ID GinRequestID gave the request ctx belongs to, or
+// "" if there is none
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// assignRequestID sets up c's request ID and the logger for its context
+func (l *Logger) assignRequestID(c *gin.Context) string {
+	id := c.GetHeader(RequestIDHeader)
+	if !validRequestID(id) {
+		id = newRequestID()
+		c.Request.Header.Set(RequestIDHeader, id)
+	}
+	c.Header(RequestIDHeader, id)
+
+	ctx := context.WithValue(c.Request.Context(), requestIDKey{}, id)
+	ctx = l.With("request_id", id).WithContext(ctx)
+	c.Request = c.Request.WithContext(ctx)
+	return id
+}
+
+// validRequestID reports whether id can be used as is, rejecting control
+// characters that could forge log lines
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestID {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < ' ' || id[i] == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random 128-bit ID in hex
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,75 @@
+package simplelog
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// SetMaxBackups sets how many rotated log files are kept. After each rotation
+// the oldest are deleted once there are more than n; 0 keeps them all
+func (l *Logger) SetMaxBackups(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.maxBackups = n
+}
+
+// SetMaxAge deletes rotated log files older than age, e.g. 30*24*time.Hour
+// for 30 days, each time the file is rotated; 0 keeps them regardless of age.
+// It applies together with SetMaxBackups
+func (l *Logger) SetMaxAge(age time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.maxAge = age
+}
+
+// backup is a rotated log file
+type backup struct {
+	path    string
+	modTime time.Time
+}
+
+// backups returns the rotated files of logFile named by any of patterns,
+// newest first
+func backups(patterns []string, logFile string) []backup {
+	var files []backup
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		dir, re := patternRegexp(pattern, logFile)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			path := filepath.Join(dir, e.Name())
+			if e.IsDir() || seen[path] || !re.MatchString(e.Name()) {
+				continue
+			}
+			if fi, err := e.Info(); err == nil {
+				seen[path] = true
+				files = append(files, backup{path, fi.ModTime()})
+			}
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+	return files
+}
+
+// pruneBackups deletes the rotated files of logFile beyond the keep newest and
+// those older than age. Zero disables either limit
+func pruneBackups(patterns []string, logFile string, keep int, age time.Duration) {
+	if keep <= 0 && age <= 0 {
+		return
+	}
+	cutoff := time.Now().Add(-age)
+	for i, b := range backups(patterns, logFile) {
+		if keep > 0 && i >= keep || age > 0 && b.modTime.Before(cutoff) {
+			os.Remove(b.path)
+		}
+	}
+}
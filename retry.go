@@ -0,0 +1,87 @@
+package simplelog
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy describes how network sinks retry failed deliveries, with
+// exponential backoff between attempts
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first
+	MaxAttempts int
+	// BaseDelay is the wait before the second attempt; it doubles for each one after
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between attempts
+	MaxDelay time.Duration
+	// Jitter randomizes each wait by up to this fraction of it, between 0 and 1
+	Jitter float64
+	// Sleep waits between attempts. It defaults to time.Sleep and can be
+	// replaced to test backoff without real delays
+	Sleep func(d time.Duration)
+}
+
+// DefaultRetryPolicy is used by network sinks that aren't given a policy.
+// Changing it affects sinks created afterwards
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+	Jitter:      0.2,
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as one that retrying won't fix, so RetryPolicy.Do
+// returns it immediately
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// Delay returns the wait before the given attempt, where attempt 1 is the first retry
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(d))
+	}
+	return d
+}
+
+// Do calls fn until it succeeds, returns an error marked Permanent, or the
+// attempts are used up, and returns the last error
+func (p RetryPolicy) Do(fn func() error) error {
+	sleep := p.Sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	var err error
+	for attempt := 0; attempt < p.MaxAttempts || attempt == 0; attempt++ {
+		if attempt > 0 {
+			sleep(p.Delay(attempt))
+		}
+		if err = fn(); err == nil {
+			return nil
+		}
+		var perm permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+	}
+	return err
+}
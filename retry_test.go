@@ -0,0 +1,102 @@
+package simplelog
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRetryDelaySequence(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	want := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+	for i, w := range want {
+		if got := p.Delay(i + 1); got != w {
+			t.Errorf("Delay(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+	// A large attempt count stops doubling at the cap instead of overflowing
+	if got := p.Delay(1000); got != time.Second {
+		t.Errorf("Delay(1000) = %v, want the cap", got)
+	}
+}
+
+func TestRetryDelayJitter(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Jitter: 0.2}
+	for attempt := 1; attempt <= 6; attempt++ {
+		base := RetryPolicy{BaseDelay: p.BaseDelay, MaxDelay: p.MaxDelay}.Delay(attempt)
+		lo, hi := base-base/5, base+base/5
+		for i := 0; i < 100; i++ {
+			if d := p.Delay(attempt); d < lo || d > hi {
+				t.Fatalf("Delay(%d) = %v, want within [%v, %v]", attempt, d, lo, hi)
+			}
+		}
+	}
+}
+
+func TestRetryDoBacksOff(t *testing.T) {
+	var slept []time.Duration
+	p := RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   10 * time.Millisecond,
+		MaxDelay:    25 * time.Millisecond,
+		Sleep:       func(d time.Duration) { slept = append(slept, d) },
+	}
+	failure := errors.New("unavailable")
+	calls := 0
+	err := p.Do(func() error {
+		calls++
+		return failure
+	})
+	if err != failure {
+		t.Errorf("Do = %v, want the last error", err)
+	}
+	if calls != 4 {
+		t.Errorf("fn called %d times, want 4", calls)
+	}
+	want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 25 * time.Millisecond}
+	if !reflect.DeepEqual(slept, want) {
+		t.Errorf("slept %v, want %v", slept, want)
+	}
+}
+
+func TestRetryDoStopsOnSuccess(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, Sleep: func(time.Duration) {}}
+	calls := 0
+	err := p.Do(func() error {
+		if calls++; calls < 3 {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Do = %v after %d calls, want nil after 3", err, calls)
+	}
+}
+
+func TestRetryDoPermanent(t *testing.T) {
+	var slept []time.Duration
+	p := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, Sleep: func(d time.Duration) { slept = append(slept, d) }}
+	rejected := errors.New("bad request")
+	calls := 0
+	err := p.Do(func() error {
+		calls++
+		return Permanent(rejected)
+	})
+	if err != rejected {
+		t.Errorf("Do = %v, want the unwrapped permanent error", err)
+	}
+	if calls != 1 || len(slept) != 0 {
+		t.Errorf("fn called %d times and slept %v, want one call and no sleep", calls, slept)
+	}
+	if Permanent(nil) != nil {
+		t.Error("Permanent(nil) != nil")
+	}
+}
@@ -0,0 +1,137 @@
+package simplelog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultSDID is the SD-ID of the structured data element holding an entry's
+// fields. 32473 is the enterprise number reserved for examples; use your own
+// organization's for an ID others won't reuse
+const DefaultSDID = "fields@32473"
+
+// programName and processID are looked up once for the APP-NAME and PROCID
+// of every message
+var (
+	programName = sync.OnceValue(func() string { return filepath.Base(os.Args[0]) })
+	processID   = sync.OnceValue(os.Getpid)
+)
+
+// RFC5424Formatter renders entries as RFC 5424 syslog messages, with the
+// caller and fields as structured data, for relaying through any syslog
+// collector:
+//
+//	<14>1 2024-01-02T10:00:00.000000Z web01 api 4242 http [fields@32473 caller="main.go:12" user_id="42"] user logged in
+type RFC5424Formatter struct {
+	// Facility is the syslog facility; it defaults to 1, user-level messages
+	Facility int
+	// Hostname defaults to os.Hostname()
+	Hostname string
+	// AppName defaults to the program's name
+	AppName string
+	// MsgID identifies the type of message; it defaults to the logger name, see
+	// Logger.Named, or "-" if there is none
+	MsgID string
+	// SDID is the SD-ID of the element holding the fields; it defaults to DefaultSDID
+	SDID string
+}
+
+func (f *RFC5424Formatter) Format(entry Entry) ([]byte, error) {
+	facility := f.Facility
+	if facility == 0 {
+		facility = 1
+	}
+	host := f.Hostname
+	if host == "" {
+		host = hostname()
+	}
+	app := f.AppName
+	if app == "" {
+		app = programName()
+	}
+	msgID := f.MsgID
+	if msgID == "" {
+		msgID = entry.Logger
+	}
+	sdID := f.SDID
+	if sdID == "" {
+		sdID = DefaultSDID
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %d %s ",
+		facility*8+syslogSeverity(entry.Level),
+		entry.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+		sdHeader(host, 255), sdHeader(app, 48), processID(), sdHeader(msgID, 32))
+
+	var params []string
+	if entry.File != "" {
+		params = append(params, sdParam("caller", entry.File+":"+strconv.Itoa(entry.Line)))
+	}
+	if entry.Function != "" {
+		params = append(params, sdParam("func", entry.Function))
+	}
+	for i, field := range entry.Fields {
+		params = append(params, sdParam(field.Key, entry.fieldText(i, QuoteNever)))
+	}
+	if len(params) == 0 {
+		b.WriteByte('-')
+	} else {
+		b.WriteString("[" + sdName(sdID) + " " + strings.Join(params, " ") + "]")
+	}
+
+	b.WriteByte(' ')
+	b.WriteString(entry.Message)
+	if entry.Stack != "" {
+		b.WriteString("\n" + entry.Stack)
+	}
+	b.WriteByte('\n')
+	return []byte(b.String()), nil
+}
+
+// sdHeader returns s as a header field: printable ASCII of at most max
+// bytes, or "-" if empty
+func sdHeader(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "-"
+	}
+	if len(s) > max {
+		s = s[:max]
+	}
+	return s
+}
+
+// sdName returns s as an SD-NAME, which can't contain '=', ' ', ']' or '"'
+// and is at most 32 bytes, except for SD-IDs holding an '@'
+func sdName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "_"
+	}
+	if len(s) > 32 && !strings.Contains(s, "@") {
+		s = s[:32]
+	}
+	return s
+}
+
+var sdValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// sdParam returns an SD-PARAM, name="value", escaping the value
+func sdParam(name, value string) string {
+	return sdName(name) + `="` + sdValueEscaper.Replace(value) + `"`
+}
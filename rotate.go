@@ -0,0 +1,84 @@
+package simplelog
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// rotatingFile is an append-only log file that is renamed aside with a
+// timestamp suffix once it grows past maxSize
+type rotatingFile struct {
+	name    string
+	file    *os.File
+	size    int64
+	maxSize int64
+}
+
+func openRotatingFile(name string, maxSize int64) (*rotatingFile, error) {
+	f := &rotatingFile{name: name, maxSize: maxSize}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	f.file = file
+	f.size = 0
+	if fi, err := file.Stat(); err == nil {
+		f.size = fi.Size()
+	}
+	return nil
+}
+
+// Write appends b, rotating first if it would take the file past maxSize. If
+// the rotation fails, b is still written to the live file when it is open,
+// and the rotation error is returned. A file that couldn't be opened again
+// after a rotation is retried on the next write
+func (f *rotatingFile) Write(b []byte) (int, error) {
+	var rotateErr error
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	} else if f.maxSize > 0 && f.size > 0 && f.size+int64(len(b)) > f.maxSize {
+		rotateErr = f.rotate()
+	}
+	if f.file == nil {
+		return 0, rotateErr
+	}
+	n, err := f.file.Write(b)
+	f.size += int64(n)
+	if rotateErr != nil {
+		return n, rotateErr
+	}
+	return n, err
+}
+
+// rotate renames the file aside and opens a new one. If the rename fails the
+// live file is reopened and the rename error returned. If the open fails,
+// f.file is left nil
+func (f *rotatingFile) rotate() error {
+	f.file.Close()
+	f.file = nil
+	renameErr := os.Rename(f.name, f.name+"."+time.Now().Format("2006-01-02-15-04-05"))
+	if err := f.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return fmt.Errorf("simplelog: rotating log file: %w", renameErr)
+	}
+	return nil
+}
+
+func (f *rotatingFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
@@ -0,0 +1,85 @@
+package simplelog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileReportsFailedRename(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.log")
+	f, err := openRotatingFile(name, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Write([]byte("first line\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Directories in the way of the rotated names make the rename fail
+	now := time.Now()
+	for _, at := range []time.Time{now, now.Add(time.Second)} {
+		dir := name + "." + at.Format("2006-01-02-15-04-05")
+		if err := os.MkdirAll(filepath.Join(dir, "x"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := f.Write([]byte("second line\n")); err == nil {
+		t.Error("Write succeeded although the file could not be rotated")
+	}
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "first line\nsecond line\n") {
+		t.Errorf("entries lost after the failed rotation: %q", b)
+	}
+}
+
+func TestRotatingFileRetriesFailedOpen(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(dir, "app.log")
+	f, err := openRotatingFile(name, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Write([]byte("first line\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replace the directory with a file, so the log file can neither be
+	// renamed aside nor opened again
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("lost line\n")); err == nil {
+		t.Error("Write succeeded although the file could not be opened")
+	}
+	if f.file != nil {
+		t.Error("closed file kept after the failed open")
+	}
+
+	if err := os.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("recovered\n")); err != nil {
+		t.Fatalf("Write once the directory is back: %v", err)
+	}
+	b, err := os.ReadFile(name)
+	if err != nil || string(b) != "recovered\n" {
+		t.Errorf("got %q, %v, want the entry written after the failure cleared", b, err)
+	}
+}
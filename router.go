@@ -0,0 +1,146 @@
+package simplelog
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+// FileRouter is a Sink that writes each entry to a file chosen by the value of
+// one of its fields, e.g. a file per tenant. Each file rotates independently.
+//
+// Files are opened lazily and kept open for reuse, but at most maxOpen at a
+// time: the least recently used one is closed when another is needed. Every
+// open file holds a file descriptor, so keep maxOpen well below the process's
+// descriptor limit (ulimit -n) minus what the rest of the program uses
+type FileRouter struct {
+	mu          sync.Mutex
+	key         string
+	template    string
+	defaultName string
+	maxOpen     int
+	maxSize     int64
+	formatter   Formatter
+	files       map[string]*list.Element
+	lru         *list.List
+}
+
+type routedFile struct {
+	name string
+	file *rotatingFile
+}
+
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// NewFileRouter creates a FileRouter that routes on the field key. The file
+// name is template with %s replaced by the field's value; entries without the
+// field, or with a value that isn't usable in a file name, go to defaultFile
+func NewFileRouter(key, template, defaultFile string, maxOpen int) *FileRouter {
+	if maxOpen < 1 {
+		maxOpen = 1
+	}
+	return &FileRouter{
+		key:         key,
+		template:    template,
+		defaultName: defaultFile,
+		maxOpen:     maxOpen,
+		maxSize:     10 * 1024 * 1024,
+		formatter:   TextFormatter{},
+		files:       make(map[string]*list.Element),
+		lru:         list.New(),
+	}
+}
+
+// SetMaxFileSize sets the size each routed file is rotated at
+func (r *FileRouter) SetMaxFileSize(size int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.maxSize = size
+}
+
+// SetFormatter sets the formatter used for the routed files
+func (r *FileRouter) SetFormatter(f Formatter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.formatter = f
+}
+
+// WriteEntry writes entry to the file for its routing field
+func (r *FileRouter) WriteEntry(entry Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, err := r.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	f, err := r.fileFor(r.nameFor(entry))
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	return err
+}
+
+// Close closes all open files
+func (r *FileRouter) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err error
+	for e := r.lru.Front(); e != nil; e = e.Next() {
+		if cerr := e.Value.(*routedFile).file.Close(); err == nil {
+			err = cerr
+		}
+	}
+	r.files = make(map[string]*list.Element)
+	r.lru.Init()
+	return err
+}
+
+// nameFor returns the file entry is routed to. Values are restricted to
+// characters safe in a file name so that they can't escape the template's directory
+func (r *FileRouter) nameFor(entry Entry) string {
+	for _, field := range entry.Fields {
+		if field.Key != r.key {
+			continue
+		}
+		v := unsafePathChars.ReplaceAllString(fmt.Sprint(field.Value), "_")
+		if v == "" || v == "." || v == ".." {
+			break
+		}
+		return fmt.Sprintf(r.template, v)
+	}
+	return r.defaultName
+}
+
+// fileFor returns the open file for name, opening it and closing the least
+// recently used file if needed. r.mu must be held
+func (r *FileRouter) fileFor(name string) (*rotatingFile, error) {
+	if e, ok := r.files[name]; ok {
+		r.lru.MoveToFront(e)
+		return e.Value.(*routedFile).file, nil
+	}
+
+	for r.lru.Len() >= r.maxOpen {
+		oldest := r.lru.Back()
+		rf := r.lru.Remove(oldest).(*routedFile)
+		delete(r.files, rf.name)
+		rf.file.Close()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return nil, err
+	}
+	f, err := openRotatingFile(name, r.maxSize)
+	if err != nil {
+		return nil, err
+	}
+	r.files[name] = r.lru.PushFront(&routedFile{name: name, file: f})
+	return f, nil
+}
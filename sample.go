@@ -0,0 +1,88 @@
+package simplelog
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// sampleSlots is the number of counters messages are hashed into; messages
+// sharing a slot are sampled together
+const sampleSlots = 4096
+
+// sampler keeps the first entries of each message per tick and then every
+// thereafter'th
+type sampler struct {
+	mu         sync.Mutex
+	tick       time.Duration
+	first      uint64
+	thereafter uint64
+	counters   [sampleSlots]sampleCounter
+}
+
+type sampleCounter struct {
+	start   time.Time
+	n       uint64
+	dropped uint64
+}
+
+// allow reports whether an entry with msg at level is kept, and if so how
+// many entries with the same message were dropped since the last one kept
+func (s *sampler) allow(level LogLevel, msg string) (bool, uint64) {
+	h := fnv.New32a()
+	h.Write([]byte{byte(level)})
+	h.Write([]byte(msg))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := &s.counters[h.Sum32()%sampleSlots]
+	now := time.Now()
+	if now.Sub(c.start) >= s.tick {
+		c.start, c.n = now, 0
+	}
+	c.n++
+	if c.n <= s.first || s.thereafter > 0 && (c.n-s.first)%s.thereafter == 0 {
+		dropped := c.dropped
+		c.dropped = 0
+		return true, dropped
+	}
+	c.dropped++
+	return false, 0
+}
+
+// SetSampling keeps the first entries with the same level and message in
+// each tick, then only every thereafter'th, e.g. SetSampling(time.Second,
+// 100, 10). A thereafter of 0 drops the rest of the tick. A kept entry that
+// follows dropped ones gets the field sampled with the number dropped.
+// A tick of 0 turns sampling off
+func (l *Logger) SetSampling(tick time.Duration, first, thereafter int) {
+	if tick <= 0 {
+		l.sampler.Store(nil)
+		return
+	}
+	l.sampler.Store(&sampler{tick: tick, first: uint64(first), thereafter: uint64(thereafter)})
+}
+
+// Sampled returns the number of entries dropped by sampling
+func (l *Logger) Sampled() uint64 {
+	return l.sampleDropped.Load()
+}
+
+// sampleAllowed reports whether an entry is kept by sampling, adding the
+// sampled field to fields if entries were dropped before it
+func (l *Logger) sampleAllowed(level LogLevel, msg string, fields *[]Field) bool {
+	s := l.sampler.Load()
+	if s == nil {
+		return true
+	}
+	ok, dropped := s.allow(level, msg)
+	if !ok {
+		l.sampleDropped.Add(1)
+		return false
+	}
+	if dropped > 0 {
+		*fields = append((*fields)[:len(*fields):len(*fields)], Field{Key: "sampled", Value: dropped})
+	}
+	return true
+}
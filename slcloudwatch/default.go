@@ -0,0 +1,49 @@
+package slcloudwatch
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/smithy-go"
+)
+
+// NewDefault creates a Sink with a client built from the default AWS
+// configuration, the environment, shared config files or the task or
+// function role as on ECS and Lambda, and creates the log group and stream
+// if they don't exist yet. Without the logs:CreateLogGroup or
+// logs:CreateLogStream permission, as with roles limited to
+// logs:PutLogEvents, the group or stream is assumed to exist
+func NewDefault(ctx context.Context, group, stream string, opts ...Option) (*Sink, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	client := cloudwatchlogs.NewFromConfig(cfg)
+
+	_, err = client.CreateLogGroup(ctx, &cloudwatchlogs.CreateLogGroupInput{LogGroupName: aws.String(group)})
+	if err != nil && !alreadyExists(err) && !accessDenied(err) {
+		return nil, err
+	}
+	_, err = client.CreateLogStream(ctx, &cloudwatchlogs.CreateLogStreamInput{
+		LogGroupName:  aws.String(group),
+		LogStreamName: aws.String(stream),
+	})
+	if err != nil && !alreadyExists(err) && !accessDenied(err) {
+		return nil, err
+	}
+	return New(client, group, stream, opts...), nil
+}
+
+func alreadyExists(err error) bool {
+	var exists *types.ResourceAlreadyExistsException
+	return errors.As(err, &exists)
+}
+
+func accessDenied(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "AccessDeniedException"
+}
@@ -0,0 +1,261 @@
+// Package slcloudwatch delivers simplelog entries to an AWS CloudWatch Logs stream
+package slcloudwatch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/base-go/simplelog"
+)
+
+// PutLogEvents limits, see
+// https://docs.aws.amazon.com/AmazonCloudWatchLogs/latest/APIReference/API_PutLogEvents.html
+const (
+	maxBatchEvents = 10000
+	maxBatchBytes  = 1048576
+	eventOverhead  = 26
+	maxEventBytes  = 256*1024 - eventOverhead
+)
+
+// Client is the part of the CloudWatch Logs API used by Sink; *cloudwatchlogs.Client implements it
+type Client interface {
+	PutLogEvents(ctx context.Context, params *cloudwatchlogs.PutLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutLogEventsOutput, error)
+}
+
+// Sink batches entries and sends them to a CloudWatch Logs stream from a
+// background goroutine. Entries that can't be queued or delivered are dropped
+// and counted
+type Sink struct {
+	client        Client
+	group         string
+	stream        string
+	formatter     simplelog.Formatter
+	flushInterval time.Duration
+	retry         simplelog.RetryPolicy
+
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan simplelog.Entry
+	done    chan struct{}
+	token   *string
+	dropped atomic.Uint64
+}
+
+// Option configures a Sink
+type Option func(*Sink)
+
+// WithFlushInterval sets how often queued entries are sent. The default is 5 seconds
+func WithFlushInterval(d time.Duration) Option {
+	return func(s *Sink) {
+		s.flushInterval = d
+	}
+}
+
+// WithQueueSize sets how many entries may wait to be sent before new ones are
+// dropped. The default is 10000
+func WithQueueSize(n int) Option {
+	return func(s *Sink) {
+		s.queue = make(chan simplelog.Entry, n)
+	}
+}
+
+// WithRetryPolicy sets how failed batches are retried before being dropped.
+// The default is simplelog.DefaultRetryPolicy
+func WithRetryPolicy(p simplelog.RetryPolicy) Option {
+	return func(s *Sink) {
+		s.retry = p
+	}
+}
+
+// WithFormatter sets the formatter used to render each event's message.
+// The default is simplelog.JSONFormatter, whose fields CloudWatch Logs
+// Insights discovers automatically
+func WithFormatter(f simplelog.Formatter) Option {
+	return func(s *Sink) {
+		s.formatter = f
+	}
+}
+
+// New creates a Sink writing to the given log group and stream, which must
+// already exist. Register it with Logger.AddSink and Close it on shutdown
+func New(client Client, group, stream string, opts ...Option) *Sink {
+	s := &Sink{
+		client:        client,
+		group:         group,
+		stream:        stream,
+		formatter:     &simplelog.JSONFormatter{},
+		flushInterval: 5 * time.Second,
+		retry:         simplelog.DefaultRetryPolicy,
+		queue:         make(chan simplelog.Entry, 10000),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	go s.run()
+	return s
+}
+
+// WriteEntry queues entry for delivery without blocking
+func (s *Sink) WriteEntry(entry simplelog.Entry) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		s.dropped.Add(1)
+		return errors.New("slcloudwatch: sink is closed")
+	}
+	select {
+	case s.queue <- entry:
+		return nil
+	default:
+		s.dropped.Add(1)
+		return errors.New("slcloudwatch: queue is full")
+	}
+}
+
+// Dropped returns the number of entries that were not delivered
+func (s *Sink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close sends any queued entries and stops the background goroutine
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	<-s.done
+	return nil
+}
+
+func (s *Sink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	var batch []types.InputLogEvent
+	size := 0
+	flush := func() {
+		if len(batch) > 0 {
+			s.put(batch)
+			batch, size = nil, 0
+		}
+	}
+
+	for {
+		select {
+		case entry, ok := <-s.queue:
+			if !ok {
+				flush()
+				return
+			}
+			event, ok := s.event(entry)
+			if !ok {
+				s.dropped.Add(1)
+				continue
+			}
+			n := len(*event.Message) + eventOverhead
+			if len(batch) == maxBatchEvents || size+n > maxBatchBytes {
+				flush()
+			}
+			batch = append(batch, event)
+			size += n
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// event converts entry to a log event, truncating messages over the per-event limit
+func (s *Sink) event(entry simplelog.Entry) (types.InputLogEvent, bool) {
+	b, err := s.formatter.Format(entry)
+	if err != nil {
+		return types.InputLogEvent{}, false
+	}
+
+	msg := strings.TrimSuffix(string(b), "\n")
+	if len(msg) > maxEventBytes {
+		cut := maxEventBytes
+		for cut > 0 && !utf8.RuneStart(msg[cut]) {
+			cut--
+		}
+		msg = msg[:cut]
+	}
+	return types.InputLogEvent{
+		Message:   aws.String(msg),
+		Timestamp: aws.Int64(entry.Time.UnixMilli()),
+	}, true
+}
+
+// put sends batch, following sequence token corrections and backing off on
+// throttling and other transient errors. The batch is dropped once retries
+// are exhausted or the error can't be fixed by retrying
+func (s *Sink) put(batch []types.InputLogEvent) {
+	err := s.retry.Do(func() error {
+		err := s.putOnce(batch)
+
+		// A stale sequence token is corrected by the error itself, so retry
+		// straight away rather than waiting out a backoff
+		var badToken *types.InvalidSequenceTokenException
+		if errors.As(err, &badToken) {
+			s.token = badToken.ExpectedSequenceToken
+			err = s.putOnce(batch)
+		}
+
+		var accepted *types.DataAlreadyAcceptedException
+		switch {
+		case errors.As(err, &accepted):
+			s.token = accepted.ExpectedSequenceToken
+			return nil
+		case permanent(err):
+			return simplelog.Permanent(err)
+		}
+		return err
+	})
+	if err != nil {
+		s.dropped.Add(uint64(len(batch)))
+	}
+}
+
+func (s *Sink) putOnce(batch []types.InputLogEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	out, err := s.client.PutLogEvents(ctx, &cloudwatchlogs.PutLogEventsInput{
+		LogGroupName:  aws.String(s.group),
+		LogStreamName: aws.String(s.stream),
+		LogEvents:     batch,
+		SequenceToken: s.token,
+	})
+	if err != nil {
+		return err
+	}
+	s.token = out.NextSequenceToken
+	return nil
+}
+
+// permanent reports whether err will fail again however often it is retried
+func permanent(err error) bool {
+	var (
+		notFound  *types.ResourceNotFoundException
+		invalid   *types.InvalidParameterException
+		denied    *types.AccessDeniedException
+		badClient *types.UnrecognizedClientException
+	)
+	return errors.As(err, &notFound) || errors.As(err, &invalid) ||
+		errors.As(err, &denied) || errors.As(err, &badClient)
+}
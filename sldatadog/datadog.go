@@ -0,0 +1,185 @@
+// Package sldatadog ships simplelog entries to the Datadog logs intake API
+package sldatadog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/base-go/simplelog"
+	"github.com/base-go/simplelog/internal/batch"
+)
+
+// Intake limits, see https://docs.datadoghq.com/api/latest/logs/#send-logs
+const (
+	maxBatchEntries = 1000
+	maxBatchBytes   = 5 << 20
+	maxEntryBytes   = 1 << 20
+)
+
+// Sink sends entries to Datadog over HTTPS in batches from a background
+// goroutine. Entries that can't be queued or delivered are dropped and counted
+type Sink struct {
+	*batch.Queue
+
+	url       string
+	client    *http.Client
+	header    http.Header
+	formatter simplelog.Formatter
+	gzip      bool
+
+	// Entries of batch seq already accepted by earlier posts, skipped when
+	// the batch is retried. Only the queue goroutine uses them
+	seq  uint64
+	sent int
+}
+
+// Option configures a Sink
+type Option func(*sinkConfig)
+
+type sinkConfig struct {
+	batch     batch.Config
+	site      string
+	client    *http.Client
+	formatter simplelog.Formatter
+	gzip      bool
+}
+
+// WithSite sets the Datadog site, e.g. "datadoghq.eu". The default is DD_SITE
+// or, if unset, "datadoghq.com"
+func WithSite(site string) Option {
+	return func(cfg *sinkConfig) {
+		cfg.site = site
+	}
+}
+
+// WithFlushInterval sets how often queued entries are sent. The default is 5 seconds
+func WithFlushInterval(d time.Duration) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.FlushInterval = d
+	}
+}
+
+// WithQueueSize sets how many entries may wait to be sent before new ones are
+// dropped. The default is 10000
+func WithQueueSize(n int) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.QueueSize = n
+	}
+}
+
+// WithRetryPolicy sets how failed batches are retried before being dropped.
+// The default is simplelog.DefaultRetryPolicy
+func WithRetryPolicy(p simplelog.RetryPolicy) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.Retry = p
+	}
+}
+
+// WithHTTPClient sets the client requests are sent with
+func WithHTTPClient(c *http.Client) Option {
+	return func(cfg *sinkConfig) {
+		cfg.client = c
+	}
+}
+
+// WithFormatter sets the formatter rendering each log. It must produce a JSON
+// object; the default is a simplelog.DatadogFormatter for service
+func WithFormatter(f simplelog.Formatter) Option {
+	return func(cfg *sinkConfig) {
+		cfg.formatter = f
+	}
+}
+
+// WithGzip compresses requests
+func WithGzip() Option {
+	return func(cfg *sinkConfig) {
+		cfg.gzip = true
+	}
+}
+
+// New creates a Sink authenticating with apiKey and tagging logs with
+// service. Register it with Logger.AddSink and Close it on shutdown
+func New(apiKey, service string, opts ...Option) *Sink {
+	cfg := sinkConfig{
+		batch: batch.Config{
+			Name:          "sldatadog",
+			QueueSize:     10000,
+			BatchSize:     maxBatchEntries,
+			FlushInterval: 5 * time.Second,
+			Retry:         simplelog.DefaultRetryPolicy,
+		},
+		site:      os.Getenv("DD_SITE"),
+		client:    &http.Client{Timeout: 30 * time.Second},
+		formatter: &simplelog.DatadogFormatter{Service: service},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.site == "" {
+		cfg.site = "datadoghq.com"
+	}
+
+	s := &Sink{
+		url:       "https://http-intake.logs." + cfg.site + "/api/v2/logs",
+		client:    cfg.client,
+		header:    http.Header{"Content-Type": {"application/json"}, "Dd-Api-Key": {apiKey}},
+		formatter: cfg.formatter,
+		gzip:      cfg.gzip,
+	}
+	if s.gzip {
+		s.header.Set("Content-Encoding", "gzip")
+	}
+	s.Queue = batch.NewSeq(cfg.batch, s.send)
+	return s
+}
+
+// send posts entries as JSON arrays, more than one if they exceed the
+// payload limit. Entries over the per-log limit are left out. A retry of the
+// same batch resumes after the posts that succeeded
+func (s *Sink) send(seq uint64, entries []simplelog.Entry) error {
+	if seq != s.seq {
+		s.seq, s.sent = seq, 0
+	}
+	var body bytes.Buffer
+	for i := s.sent; i < len(entries); i++ {
+		b, err := s.formatter.Format(entries[i])
+		if err != nil || len(b) > maxEntryBytes {
+			continue
+		}
+		b = bytes.TrimSuffix(b, []byte{'\n'})
+		if body.Len() > 0 && body.Len()+len(b)+2 > maxBatchBytes {
+			if err := s.post(&body); err != nil {
+				return err
+			}
+			s.sent = i
+			body.Reset()
+		}
+		if body.Len() == 0 {
+			body.WriteByte('[')
+		} else {
+			body.WriteByte(',')
+		}
+		body.Write(b)
+	}
+	if body.Len() == 0 {
+		return nil
+	}
+	return s.post(&body)
+}
+
+// post sends a JSON array missing its closing bracket
+func (s *Sink) post(body *bytes.Buffer) error {
+	body.WriteByte(']')
+	payload := body.Bytes()
+	if s.gzip {
+		var zipped bytes.Buffer
+		w := gzip.NewWriter(&zipped)
+		w.Write(payload)
+		w.Close()
+		payload = zipped.Bytes()
+	}
+	return batch.Post(s.client, s.url, s.header, bytes.NewReader(payload))
+}
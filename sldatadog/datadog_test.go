@@ -0,0 +1,47 @@
+package sldatadog
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/base-go/simplelog"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestRetryResumesAfterAcceptedPosts(t *testing.T) {
+	var posts []int
+	fail := true
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(r.Body)
+		n := strings.Count(string(b), `"message"`)
+		if len(posts) == 1 && fail {
+			fail = false
+			return nil, errors.New("connection reset")
+		}
+		posts = append(posts, n)
+		return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(""))}, nil
+	})}
+	s := New("key", "app", WithHTTPClient(client))
+	defer s.Close()
+
+	// Each entry is close to 1MB, so the batch is split over two posts
+	entries := make([]simplelog.Entry, 8)
+	for i := range entries {
+		entries[i] = simplelog.Entry{Level: simplelog.INFO, Message: strings.Repeat("x", 900<<10)}
+	}
+	if err := s.send(1, entries); err == nil {
+		t.Fatal("send succeeded although the second post failed")
+	}
+	if err := s.send(1, entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 2 || posts[0]+posts[1] != len(entries) {
+		t.Errorf("posted %v entries, want all %d once", posts, len(entries))
+	}
+}
@@ -0,0 +1,98 @@
+// Package slecho logs requests served by Echo with simplelog, in the same
+// format as simplelog's Gin middleware
+package slecho
+
+import (
+	"time"
+
+	"github.com/base-go/simplelog"
+	"github.com/labstack/echo/v4"
+)
+
+// Option configures the middleware returned by Middleware
+type Option func(*config)
+
+type config struct {
+	statusLevel func(status int) simplelog.LogLevel
+	handlerName bool
+	format      func(req simplelog.Request) string
+}
+
+// WithStatusLevel sets the function choosing the level a request is logged at
+// from its response status. The default is simplelog.DefaultStatusLevel
+func WithStatusLevel(fn func(status int) simplelog.LogLevel) Option {
+	return func(cfg *config) {
+		cfg.statusLevel = fn
+	}
+}
+
+// WithHandlerName adds a handler field naming the route that served the
+// request. It is omitted for requests that matched no route
+func WithHandlerName() Option {
+	return func(cfg *config) {
+		cfg.handlerName = true
+	}
+}
+
+// WithFormat sets the function building the message for each request in
+// place of the default "Request: ..." line, e.g. simplelog.CombinedLogFormat
+func WithFormat(fn func(req simplelog.Request) string) Option {
+	return func(cfg *config) {
+		cfg.format = fn
+	}
+}
+
+// Middleware returns an Echo middleware logging each request to l. Errors
+// returned by later handlers are passed to Echo's HTTPErrorHandler before
+// logging, so that the logged status is the one sent, and are not returned
+// again
+func Middleware(l *simplelog.Logger, opts ...Option) echo.MiddlewareFunc {
+	cfg := config{statusLevel: simplelog.DefaultStatusLevel}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			req := c.Request()
+			path := req.URL.Path
+			if req.URL.RawQuery != "" {
+				path = path + "?" + req.URL.RawQuery
+			}
+
+			var errs string
+			if err := next(c); err != nil {
+				c.Error(err)
+				errs = err.Error()
+			}
+
+			latency := time.Since(start)
+			status := c.Response().Status
+
+			var fields []simplelog.Field
+			if cfg.handlerName && c.Path() != "" {
+				fields = append(fields, simplelog.Field{Key: "handler", Value: c.Path()})
+			}
+
+			// Fields the context extractors find, such as trace IDs, come
+			// from the request's context
+			user, _, _ := req.BasicAuth()
+			l.Ctx(req.Context()).LogRequestFormat(cfg.statusLevel(status), simplelog.Request{
+				Method:    req.Method,
+				Path:      path,
+				Status:    status,
+				ClientIP:  c.RealIP(),
+				Latency:   latency,
+				UserAgent: req.UserAgent(),
+				Errors:    errs,
+				Time:      start,
+				Proto:     req.Proto,
+				Size:      int(c.Response().Size),
+				Referer:   req.Referer(),
+				User:      user,
+			}, cfg.format, fields...)
+			return nil
+		}
+	}
+}
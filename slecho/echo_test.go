@@ -0,0 +1,53 @@
+package slecho
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/base-go/simplelog"
+	"github.com/labstack/echo/v4"
+)
+
+func TestMiddlewareCombinedFormat(t *testing.T) {
+	l := simplelog.NewWriter(simplelog.INFO, io.Discard)
+	var got string
+	l.AddHook(func(entry simplelog.Entry) { got = entry.Message })
+	e := echo.New()
+	e.Use(Middleware(l, WithFormat(simplelog.CombinedLogFormat)))
+	e.GET("/x", func(c echo.Context) error { return c.String(http.StatusOK, "hello") })
+
+	req := httptest.NewRequest(http.MethodGet, "/x?a=1", nil)
+	req.SetBasicAuth("alice", "secret")
+	req.Header.Set("Referer", "https://example.com/")
+	req.Header.Set("User-Agent", "test-agent")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := regexp.MustCompile(`^192\.0\.2\.1 - alice \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /x\?a=1 HTTP/1\.1" 200 5 "https://example\.com/" "test-agent"$`)
+	if !want.MatchString(got) {
+		t.Errorf("got %q, want a complete combined log line", got)
+	}
+}
+
+type traceKey struct{}
+
+func TestMiddlewareUsesRequestContext(t *testing.T) {
+	l := simplelog.NewWriter(simplelog.INFO, io.Discard)
+	l.AddContextExtractor(simplelog.ContextValue(traceKey{}, "trace_id"))
+	var fields []simplelog.Field
+	l.AddHook(func(entry simplelog.Entry) { fields = entry.Fields })
+	e := echo.New()
+	e.Use(Middleware(l))
+	e.GET("/x", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/x", nil)
+	req = req.WithContext(context.WithValue(req.Context(), traceKey{}, "abc123"))
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(fields) != 1 || fields[0].Key != "trace_id" || fields[0].Value != "abc123" {
+		t.Errorf("got fields %v, want trace_id from the request context", fields)
+	}
+}
@@ -0,0 +1,284 @@
+// Package slelastic indexes simplelog entries into Elasticsearch or
+// OpenSearch with the bulk API
+package slelastic
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/base-go/simplelog"
+	"github.com/base-go/simplelog/internal/batch"
+)
+
+// Sink indexes entries in batches from a background goroutine. Each document
+// gets an ID derived from the sink, its batch and its place in the batch, so
+// resending a batch after a partial failure doesn't index entries twice while
+// identical entries are still indexed separately. Entries that can't be
+// queued, indexed or delivered are dropped and counted
+type Sink struct {
+	*batch.Queue
+
+	url      string
+	client   *http.Client
+	header   http.Header
+	index    string
+	nonce    [8]byte
+	rejected atomic.Uint64
+}
+
+// Option configures a Sink
+type Option func(*sinkConfig)
+
+type sinkConfig struct {
+	batch  batch.Config
+	client *http.Client
+	header http.Header
+}
+
+// WithFlushInterval sets how often queued entries are sent. The default is 5 seconds
+func WithFlushInterval(d time.Duration) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.FlushInterval = d
+	}
+}
+
+// WithBatchSize sets the most entries sent in one bulk request. The default is 500
+func WithBatchSize(n int) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.BatchSize = n
+	}
+}
+
+// WithQueueSize sets how many entries may wait to be sent before new ones are
+// dropped. The default is 10000
+func WithQueueSize(n int) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.QueueSize = n
+	}
+}
+
+// WithRetryPolicy sets how failed batches are retried before being dropped.
+// The default is simplelog.DefaultRetryPolicy
+func WithRetryPolicy(p simplelog.RetryPolicy) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.Retry = p
+	}
+}
+
+// WithHTTPClient sets the client requests are sent with
+func WithHTTPClient(c *http.Client) Option {
+	return func(cfg *sinkConfig) {
+		cfg.client = c
+	}
+}
+
+// WithBasicAuth authenticates requests with a user name and password
+func WithBasicAuth(user, password string) Option {
+	return WithHeader("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user+":"+password)))
+}
+
+// WithAPIKey authenticates requests with an Elasticsearch API key, as
+// returned base64-encoded by the create API key API
+func WithAPIKey(key string) Option {
+	return WithHeader("Authorization", "ApiKey "+key)
+}
+
+// WithHeader adds a header to every request
+func WithHeader(key, value string) Option {
+	return func(cfg *sinkConfig) {
+		cfg.header.Add(key, value)
+	}
+}
+
+// New creates a Sink indexing into the cluster at url, e.g.
+// "http://localhost:9200". index names the index and may contain a time
+// layout in braces, filled in from each entry's UTC time, e.g.
+// "logs-{2006.01.02}" for daily indices. Register the Sink with
+// Logger.AddSink and Close it on shutdown
+func New(url, index string, opts ...Option) *Sink {
+	cfg := sinkConfig{
+		batch: batch.Config{
+			Name:          "slelastic",
+			QueueSize:     10000,
+			BatchSize:     500,
+			FlushInterval: 5 * time.Second,
+			Retry:         simplelog.DefaultRetryPolicy,
+		},
+		client: &http.Client{Timeout: 30 * time.Second},
+		header: http.Header{"Content-Type": {"application/x-ndjson"}},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	s := &Sink{
+		url:    strings.TrimSuffix(url, "/") + "/_bulk",
+		client: cfg.client,
+		header: cfg.header,
+		index:  index,
+	}
+	rand.Read(s.nonce[:])
+	s.Queue = batch.NewSeq(cfg.batch, s.send)
+	return s
+}
+
+// Dropped returns the number of entries that were not indexed, including
+// those the cluster rejected
+func (s *Sink) Dropped() uint64 {
+	return s.Queue.Dropped() + s.rejected.Load()
+}
+
+var indexLayout = regexp.MustCompile(`\{([^}]*)\}`)
+
+// indexName returns the index entry goes to
+func (s *Sink) indexName(entry simplelog.Entry) string {
+	return indexLayout.ReplaceAllStringFunc(s.index, func(m string) string {
+		return entry.Time.UTC().Format(m[1 : len(m)-1])
+	})
+}
+
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// send indexes entries with one bulk request. Documents rejected for good,
+// e.g. by a mapping conflict, are counted; if any failed for a reason that
+// may pass, such as a full queue on the cluster, the batch is retried
+func (s *Sink) send(seq uint64, entries []simplelog.Entry) error {
+	var body bytes.Buffer
+	for i, entry := range entries {
+		src, err := json.Marshal(document(entry))
+		if err != nil {
+			continue
+		}
+		action, _ := json.Marshal(map[string]interface{}{"create": map[string]string{
+			"_index": s.indexName(entry),
+			"_id":    s.documentID(seq, i),
+		}})
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(src)
+		body.WriteByte('\n')
+	}
+	if body.Len() == 0 {
+		return nil
+	}
+
+	var resp bulkResponse
+	if err := s.post(&body, &resp); err != nil || !resp.Errors {
+		return err
+	}
+
+	var retry error
+	rejected := 0
+	for _, item := range resp.Items {
+		for _, result := range item {
+			switch {
+			// Already indexed by an earlier attempt
+			case result.Status < 300, result.Status == http.StatusConflict:
+			case result.Status == http.StatusTooManyRequests, result.Status >= 500:
+				retry = fmt.Errorf("slelastic: %d %s", result.Status, result.Error.Reason)
+			default:
+				rejected++
+			}
+		}
+	}
+	if retry != nil {
+		return retry
+	}
+	s.rejected.Add(uint64(rejected))
+	return nil
+}
+
+// documentID returns the ID of the i'th document of batch seq
+func (s *Sink) documentID(seq uint64, i int) string {
+	var b [24]byte
+	copy(b[:], s.nonce[:])
+	binary.BigEndian.PutUint64(b[8:], seq)
+	binary.BigEndian.PutUint64(b[16:], uint64(i))
+	return hex.EncodeToString(b[:])
+}
+
+// post sends a bulk request and decodes its response into resp
+func (s *Sink) post(body *bytes.Buffer, resp *bulkResponse) error {
+	req, err := http.NewRequest(http.MethodPost, s.url, body)
+	if err != nil {
+		return simplelog.Permanent(err)
+	}
+	req.Header = s.header.Clone()
+
+	r, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	if r.StatusCode/100 != 2 {
+		err := errors.New("slelastic: " + r.Status)
+		if r.StatusCode/100 == 4 && r.StatusCode != http.StatusTooManyRequests {
+			return simplelog.Permanent(err)
+		}
+		return err
+	}
+	return json.NewDecoder(r.Body).Decode(resp)
+}
+
+// document returns the source of entry's document, with its time under
+// @timestamp as Elastic's tooling expects. Fields whose key collides with one
+// of the document's own keys are written as "fields.<key>"
+func document(entry simplelog.Entry) map[string]interface{} {
+	doc := map[string]interface{}{
+		"@timestamp": entry.Time.UTC().Format(time.RFC3339Nano),
+		"level":      strings.ToLower(entry.Level.String()),
+		"message":    entry.Message,
+	}
+	if entry.Logger != "" {
+		doc["logger"] = entry.Logger
+	}
+	if entry.File != "" {
+		doc["caller"] = fmt.Sprintf("%s:%d", entry.File, entry.Line)
+	}
+	if entry.Function != "" {
+		doc["func"] = entry.Function
+	}
+	if entry.Stack != "" {
+		doc["stack"] = entry.Stack
+	}
+	for _, field := range entry.Fields {
+		key := field.Key
+		if _, ok := doc[key]; ok {
+			key = "fields." + key
+		}
+		doc[key] = value(field.Value)
+	}
+	return doc
+}
+
+// value returns v as JSON encodes it, using the message of errors and the %v
+// form of values that can't be encoded
+func value(v interface{}) interface{} {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+	if _, err := json.Marshal(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return v
+}
@@ -0,0 +1,123 @@
+// Package slfiber logs requests served by Fiber with simplelog, in the same
+// format as simplelog's Gin middleware
+package slfiber
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/base-go/simplelog"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Option configures the middleware returned by Middleware
+type Option func(*config)
+
+type config struct {
+	statusLevel func(status int) simplelog.LogLevel
+	handlerName bool
+	format      func(req simplelog.Request) string
+}
+
+// WithStatusLevel sets the function choosing the level a request is logged at
+// from its response status. The default is simplelog.DefaultStatusLevel
+func WithStatusLevel(fn func(status int) simplelog.LogLevel) Option {
+	return func(cfg *config) {
+		cfg.statusLevel = fn
+	}
+}
+
+// WithHandlerName adds a handler field naming the route that served the
+// request
+func WithHandlerName() Option {
+	return func(cfg *config) {
+		cfg.handlerName = true
+	}
+}
+
+// WithFormat sets the function building the message for each request in
+// place of the default "Request: ..." line, e.g. simplelog.CombinedLogFormat
+func WithFormat(fn func(req simplelog.Request) string) Option {
+	return func(cfg *config) {
+		cfg.format = fn
+	}
+}
+
+// Middleware returns a Fiber handler logging each request to l. Errors
+// returned by later handlers are passed to the app's ErrorHandler before
+// logging, so that the logged status is the one sent, and are not returned
+// again
+func Middleware(l *simplelog.Logger, opts ...Option) fiber.Handler {
+	cfg := config{statusLevel: simplelog.DefaultStatusLevel}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		var errs string
+		if err := c.Next(); err != nil {
+			if herr := c.App().ErrorHandler(c, err); herr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+			errs = err.Error()
+		}
+
+		latency := time.Since(start)
+		status := c.Response().StatusCode()
+
+		// The request's strings point into buffers fasthttp reuses, but
+		// LogRequestFormat formats them into the message before returning
+		var fields []simplelog.Field
+		if cfg.handlerName {
+			fields = append(fields, simplelog.Field{Key: "handler", Value: c.Route().Path})
+		}
+
+		// Fields the context extractors find, such as trace IDs, come from
+		// the user context
+		l.Ctx(c.UserContext()).LogRequestFormat(cfg.statusLevel(status), simplelog.Request{
+			Method:    c.Method(),
+			Path:      c.OriginalURL(),
+			Status:    status,
+			ClientIP:  c.IP(),
+			Latency:   latency,
+			UserAgent: c.Get(fiber.HeaderUserAgent),
+			Errors:    errs,
+			Time:      start,
+			Proto:     string(c.Request().Header.Protocol()),
+			Size:      responseSize(c.Response()),
+			Referer:   c.Get(fiber.HeaderReferer),
+			User:      basicAuthUser(c.Get(fiber.HeaderAuthorization)),
+		}, cfg.format, fields...)
+		return nil
+	}
+}
+
+// responseSize returns the length of the response body, reading it from the
+// Content-Length header for streamed bodies, which Body would consume
+func responseSize(resp *fiber.Response) int {
+	if resp.IsBodyStream() {
+		return max(resp.Header.ContentLength(), 0)
+	}
+	return len(resp.Body())
+}
+
+// basicAuthUser returns the user name from a basic Authorization header, or
+// "" if there is none
+func basicAuthUser(header string) string {
+	const prefix = "Basic "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return ""
+	}
+	b, err := base64.StdEncoding.DecodeString(header[len(prefix):])
+	if err != nil {
+		return ""
+	}
+	user, _, ok := strings.Cut(string(b), ":")
+	if !ok {
+		return ""
+	}
+	return user
+}
@@ -0,0 +1,63 @@
+package slfiber
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/base-go/simplelog"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestMiddlewareCombinedFormat(t *testing.T) {
+	l := simplelog.NewWriter(simplelog.INFO, io.Discard)
+	var got string
+	l.AddHook(func(entry simplelog.Entry) { got = entry.Message })
+	app := fiber.New()
+	app.Use(Middleware(l, WithFormat(simplelog.CombinedLogFormat)))
+	app.Get("/x", func(c *fiber.Ctx) error { return c.SendString("hello") })
+
+	req := httptest.NewRequest(http.MethodGet, "/x?a=1", nil)
+	req.SetBasicAuth("alice", "secret")
+	req.Header.Set("Referer", "https://example.com/")
+	req.Header.Set("User-Agent", "test-agent")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	want := regexp.MustCompile(`^\S+ - alice \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /x\?a=1 HTTP/1\.1" 200 5 "https://example\.com/" "test-agent"$`)
+	if !want.MatchString(got) {
+		t.Errorf("got %q, want a complete combined log line", got)
+	}
+}
+
+type traceKey struct{}
+
+func TestMiddlewareUsesUserContext(t *testing.T) {
+	l := simplelog.NewWriter(simplelog.INFO, io.Discard)
+	l.AddContextExtractor(simplelog.ContextValue(traceKey{}, "trace_id"))
+	var fields []simplelog.Field
+	l.AddHook(func(entry simplelog.Entry) { fields = entry.Fields })
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.SetUserContext(context.WithValue(c.UserContext(), traceKey{}, "abc123"))
+		return c.Next()
+	})
+	app.Use(Middleware(l))
+	app.Get("/x", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/x", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if len(fields) != 1 || fields[0].Key != "trace_id" || fields[0].Value != "abc123" {
+		t.Errorf("got fields %v, want trace_id from the user context", fields)
+	}
+}
@@ -0,0 +1,265 @@
+// Package slfluent ships simplelog entries to Fluentd or Fluent Bit over the
+// forward protocol
+package slfluent
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/base-go/simplelog"
+	"github.com/base-go/simplelog/internal/batch"
+)
+
+// Sink sends entries to a forward input in batches from a background
+// goroutine, reconnecting after errors. Entries that can't be queued or
+// delivered are dropped and counted
+type Sink struct {
+	*batch.Queue
+
+	network string
+	addr    string
+	tag     string
+	ack     bool
+	timeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// Option configures a Sink
+type Option func(*sinkConfig)
+
+type sinkConfig struct {
+	batch   batch.Config
+	ack     bool
+	timeout time.Duration
+}
+
+// WithFlushInterval sets how often queued entries are sent. The default is 1 second
+func WithFlushInterval(d time.Duration) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.FlushInterval = d
+	}
+}
+
+// WithBatchSize sets the most entries sent at once. The default is 500
+func WithBatchSize(n int) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.BatchSize = n
+	}
+}
+
+// WithQueueSize sets how many entries may wait to be sent before new ones are
+// dropped. The default is 10000
+func WithQueueSize(n int) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.QueueSize = n
+	}
+}
+
+// WithRetryPolicy sets how failed batches are retried before being dropped.
+// The default is simplelog.DefaultRetryPolicy
+func WithRetryPolicy(p simplelog.RetryPolicy) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.Retry = p
+	}
+}
+
+// WithAck makes the server acknowledge each batch, which is resent if no
+// acknowledgement arrives, as with require_ack_response in Fluentd's forward
+// output
+func WithAck() Option {
+	return func(cfg *sinkConfig) {
+		cfg.ack = true
+	}
+}
+
+// WithTimeout sets the limit for connecting, writing a batch and waiting for
+// its acknowledgement. The default is 10 seconds
+func WithTimeout(d time.Duration) Option {
+	return func(cfg *sinkConfig) {
+		cfg.timeout = d
+	}
+}
+
+// New creates a Sink sending to the forward input at addr, "host:port" for
+// TCP or "unix:///path/to/socket". tag is the tag of every event and may
+// contain {level}, the entry's level in lower case, and {logger}, its logger
+// name or "default", e.g. "app.{level}". The first connection is made
+// when the first batch is sent. Register the Sink with Logger.AddSink and
+// Close it on shutdown
+func New(addr, tag string, opts ...Option) *Sink {
+	cfg := sinkConfig{
+		batch: batch.Config{
+			Name:          "slfluent",
+			QueueSize:     10000,
+			BatchSize:     500,
+			FlushInterval: time.Second,
+			Retry:         simplelog.DefaultRetryPolicy,
+		},
+		timeout: 10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	s := &Sink{network: "tcp", addr: addr, tag: tag, ack: cfg.ack, timeout: cfg.timeout}
+	if path, ok := strings.CutPrefix(addr, "unix://"); ok {
+		s.network, s.addr = "unix", path
+	}
+	s.Queue = batch.New(cfg.batch, s.send)
+	return s
+}
+
+// Close sends any queued entries and closes the connection
+func (s *Sink) Close() error {
+	err := s.Queue.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.disconnect()
+	return err
+}
+
+// tagOf returns the tag of entry's event
+func (s *Sink) tagOf(entry simplelog.Entry) string {
+	if !strings.Contains(s.tag, "{") {
+		return s.tag
+	}
+	logger := entry.Logger
+	if logger == "" {
+		logger = "default"
+	}
+	return strings.NewReplacer(
+		"{level}", strings.ToLower(entry.Level.String()),
+		"{logger}", logger,
+	).Replace(s.tag)
+}
+
+// send sends entries as one forward mode message per tag
+func (s *Sink) send(entries []simplelog.Entry) error {
+	var tags []string
+	byTag := map[string][]simplelog.Entry{}
+	for _, entry := range entries {
+		tag := s.tagOf(entry)
+		if _, ok := byTag[tag]; !ok {
+			tags = append(tags, tag)
+		}
+		byTag[tag] = append(byTag[tag], entry)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, tag := range tags {
+		if err := s.forward(tag, byTag[tag]); err != nil {
+			s.disconnect()
+			return err
+		}
+	}
+	return nil
+}
+
+// forward writes entries as a forward mode message, [tag, [[time, record]...],
+// option], and waits for its acknowledgement if enabled. s.mu must be held
+func (s *Sink) forward(tag string, entries []simplelog.Entry) error {
+	var e encoder
+	e.writeArrayHeader(3)
+	e.writeString(tag)
+	e.writeArrayHeader(len(entries))
+	for _, entry := range entries {
+		e.writeArrayHeader(2)
+		e.writeEventTime(entry.Time)
+		writeRecord(&e, entry)
+	}
+
+	var chunk string
+	e.writeMapHeader(1)
+	if s.ack {
+		var id [16]byte
+		rand.Read(id[:])
+		chunk = base64.StdEncoding.EncodeToString(id[:])
+		e.writeString("chunk")
+		e.writeString(chunk)
+	} else {
+		e.writeString("size")
+		e.writeUint(uint64(len(entries)))
+	}
+
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.network, s.addr, s.timeout)
+		if err != nil {
+			return err
+		}
+		s.conn, s.r = conn, bufio.NewReader(conn)
+	}
+	s.conn.SetDeadline(time.Now().Add(s.timeout))
+	if _, err := s.conn.Write(e.buf); err != nil {
+		return err
+	}
+	if !s.ack {
+		return nil
+	}
+
+	ack, err := readAck(s.r)
+	if err != nil {
+		return err
+	}
+	if ack != chunk {
+		return fmt.Errorf("slfluent: acknowledged chunk %q, sent %q", ack, chunk)
+	}
+	return nil
+}
+
+// disconnect closes the connection, if any. s.mu must be held
+func (s *Sink) disconnect() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn, s.r = nil, nil
+	}
+}
+
+var reservedKeys = map[string]bool{"level": true, "message": true, "logger": true, "caller": true, "func": true, "stack": true}
+
+// writeRecord writes entry's record: its level, message, logger, caller,
+// function and stack as present, then its fields. Fields whose key collides
+// with one of those keys are written as "fields.<key>"
+func writeRecord(e *encoder, entry simplelog.Entry) {
+	pairs := [][2]string{
+		{"level", strings.ToLower(entry.Level.String())},
+		{"message", entry.Message},
+	}
+	if entry.Logger != "" {
+		pairs = append(pairs, [2]string{"logger", entry.Logger})
+	}
+	if entry.File != "" {
+		pairs = append(pairs, [2]string{"caller", fmt.Sprintf("%s:%d", entry.File, entry.Line)})
+	}
+	if entry.Function != "" {
+		pairs = append(pairs, [2]string{"func", entry.Function})
+	}
+	if entry.Stack != "" {
+		pairs = append(pairs, [2]string{"stack", entry.Stack})
+	}
+
+	e.writeMapHeader(len(pairs) + len(entry.Fields))
+	for _, p := range pairs {
+		e.writeString(p[0])
+		e.writeString(p[1])
+	}
+	for _, field := range entry.Fields {
+		key := field.Key
+		if reservedKeys[key] {
+			key = "fields." + key
+		}
+		e.writeString(key)
+		e.writeValue(field.Value)
+	}
+}
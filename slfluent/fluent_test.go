@@ -0,0 +1,246 @@
+package slfluent
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/base-go/simplelog"
+)
+
+// decode reads one MessagePack value as written by encoder, with EventTime
+// extensions decoded to time.Time
+func decode(r *bufio.Reader) (interface{}, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	next := func(n int) ([]byte, error) {
+		buf := make([]byte, n)
+		_, err := io.ReadFull(r, buf)
+		return buf, err
+	}
+	length := func(n int) (int, error) {
+		buf, err := next(n)
+		var l uint64
+		for _, c := range buf {
+			l = l<<8 | uint64(c)
+		}
+		return int(l), err
+	}
+	collection := func(n int, pairs bool) (interface{}, error) {
+		if pairs {
+			m := make(map[string]interface{}, n)
+			for i := 0; i < n; i++ {
+				k, err := decode(r)
+				if err != nil {
+					return nil, err
+				}
+				if m[k.(string)], err = decode(r); err != nil {
+					return nil, err
+				}
+			}
+			return m, nil
+		}
+		a := make([]interface{}, n)
+		for i := range a {
+			if a[i], err = decode(r); err != nil {
+				return nil, err
+			}
+		}
+		return a, nil
+	}
+
+	switch {
+	case b < 0x80:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xf0 == 0x80:
+		return collection(int(b&0x0f), true)
+	case b&0xf0 == 0x90:
+		return collection(int(b&0x0f), false)
+	case b&0xe0 == 0xa0:
+		s, err := next(int(b & 0x1f))
+		return string(s), err
+	}
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2, 0xc3:
+		return b == 0xc3, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := length(1 << (b - 0xcc))
+		return int64(n), err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		buf, err := next(1 << (b - 0xd0))
+		var u uint64
+		for _, c := range buf {
+			u = u<<8 | uint64(c)
+		}
+		shift := 64 - 8*len(buf)
+		return int64(u<<shift) >> shift, err
+	case 0xcb:
+		buf, err := next(8)
+		return math.Float64frombits(binary.BigEndian.Uint64(buf)), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := length(1 << (b - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		s, err := next(n)
+		return string(s), err
+	case 0xdc, 0xdd:
+		n, err := length(2 << (b - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return collection(n, false)
+	case 0xde, 0xdf:
+		n, err := length(2 << (b - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return collection(n, true)
+	case 0xd7:
+		buf, err := next(9)
+		if err != nil || buf[0] != 0 {
+			return nil, fmt.Errorf("bad EventTime %x, %v", buf, err)
+		}
+		sec, nsec := binary.BigEndian.Uint32(buf[1:]), binary.BigEndian.Uint32(buf[5:])
+		return time.Unix(int64(sec), int64(nsec)).UTC(), nil
+	}
+	return nil, fmt.Errorf("unexpected type byte %#x", b)
+}
+
+func TestEncoderRoundTrip(t *testing.T) {
+	values := []interface{}{
+		nil, true, false,
+		int64(0), int64(127), int64(128), int64(255), int64(256), int64(65535), int64(65536), int64(math.MaxInt64),
+		int64(-1), int64(-32), int64(-33), int64(-128), int64(-129), int64(-32768), int64(-32769), int64(math.MinInt64),
+		1.5, "", "short", strings.Repeat("a", 31), strings.Repeat("b", 32), strings.Repeat("c", 256), strings.Repeat("d", 70000),
+	}
+	for _, v := range values {
+		var e encoder
+		e.writeValue(v)
+		got, err := decode(bufio.NewReader(strings.NewReader(string(e.buf))))
+		if err != nil || !reflect.DeepEqual(got, v) {
+			t.Errorf("round trip of %.40v gave %.40v, %v", v, got, err)
+		}
+	}
+
+	var e encoder
+	e.writeArrayHeader(20)
+	e.writeMapHeader(20)
+	e.writeArrayHeader(70000)
+	e.writeMapHeader(70000)
+	want := []byte{0xdc, 0, 20, 0xde, 0, 20, 0xdd, 0, 1, 0x11, 0x70, 0xdf, 0, 1, 0x11, 0x70}
+	if !reflect.DeepEqual(e.buf, want) {
+		t.Errorf("headers encoded as %x, want %x", e.buf, want)
+	}
+}
+
+// serve accepts one connection and passes on each forward message read from
+// it. If ack is set, it answers each with ack applied to the message's chunk
+func serve(t *testing.T, ack func(chunk string) string) (addr string, msgs <-chan []interface{}) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	ch := make(chan []interface{}, 10)
+	go func() {
+		defer close(ch)
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			v, err := decode(r)
+			if err != nil {
+				return
+			}
+			msg := v.([]interface{})
+			ch <- msg
+			if ack != nil {
+				var e encoder
+				e.writeMapHeader(1)
+				e.writeString("ack")
+				e.writeString(ack(msg[2].(map[string]interface{})["chunk"].(string)))
+				conn.Write(e.buf)
+			}
+		}
+	}()
+	return ln.Addr().String(), ch
+}
+
+func TestSinkForwardsWithAck(t *testing.T) {
+	addr, msgs := serve(t, func(chunk string) string { return chunk })
+	s := New(addr, "app.{level}", WithAck(), WithFlushInterval(time.Hour))
+	at := time.Date(2024, 1, 2, 10, 0, 0, 123456789, time.UTC)
+	s.WriteEntry(simplelog.Entry{Time: at, Level: simplelog.INFO, Message: "started", Logger: "api",
+		File: "main.go", Line: 12, Fields: []simplelog.Field{{Key: "port", Value: 8080}, {Key: "level", Value: "x"}}})
+	s.WriteEntry(simplelog.Entry{Time: at, Level: simplelog.ERROR, Message: "failed"})
+	s.Close()
+
+	var got [][]interface{}
+	for msg := range msgs {
+		got = append(got, msg)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d messages, want one per tag", len(got))
+	}
+	if got[0][0] != "app.info" || got[1][0] != "app.error" {
+		t.Errorf("got tags %v and %v", got[0][0], got[1][0])
+	}
+	event := got[0][1].([]interface{})[0].([]interface{})
+	if !event[0].(time.Time).Equal(at) {
+		t.Errorf("event time %v, want %v", event[0], at)
+	}
+	want := map[string]interface{}{
+		"level": "info", "message": "started", "logger": "api", "caller": "main.go:12",
+		"port": int64(8080), "fields.level": "x",
+	}
+	if !reflect.DeepEqual(event[1], want) {
+		t.Errorf("record %v, want %v", event[1], want)
+	}
+	if s.Dropped() != 0 {
+		t.Errorf("%d entries dropped", s.Dropped())
+	}
+}
+
+func TestSinkRejectsWrongAck(t *testing.T) {
+	addr, msgs := serve(t, func(string) string { return "other" })
+	s := New(addr, "app", WithAck(), WithFlushInterval(time.Hour),
+		WithRetryPolicy(simplelog.RetryPolicy{MaxAttempts: 1}))
+	s.WriteEntry(simplelog.Entry{Level: simplelog.INFO, Message: "lost"})
+	s.Close()
+	for range msgs {
+	}
+	if s.Dropped() != 1 {
+		t.Errorf("%d entries dropped, want the unacknowledged one", s.Dropped())
+	}
+}
+
+func TestSinkSizeOptionWithoutAck(t *testing.T) {
+	addr, msgs := serve(t, nil)
+	s := New(addr, "app", WithFlushInterval(time.Hour))
+	s.WriteEntry(simplelog.Entry{Level: simplelog.INFO, Message: "one"})
+	s.WriteEntry(simplelog.Entry{Level: simplelog.INFO, Message: "two"})
+	s.Close()
+
+	msg := <-msgs
+	if opt := msg[2].(map[string]interface{}); opt["size"] != int64(2) {
+		t.Errorf("got option %v, want size 2", opt)
+	}
+}
@@ -0,0 +1,233 @@
+package slfluent
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// The small part of MessagePack the forward protocol needs, see
+// https://github.com/msgpack/msgpack/blob/master/spec.md
+
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) writeNil() {
+	e.buf = append(e.buf, 0xc0)
+}
+
+func (e *encoder) writeBool(b bool) {
+	if b {
+		e.buf = append(e.buf, 0xc3)
+	} else {
+		e.buf = append(e.buf, 0xc2)
+	}
+}
+
+func (e *encoder) writeInt(i int64) {
+	switch {
+	case i >= 0:
+		e.writeUint(uint64(i))
+	case i >= -32:
+		e.buf = append(e.buf, byte(i))
+	case i >= math.MinInt8:
+		e.buf = append(e.buf, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, 0xd1), uint16(i))
+	case i >= math.MinInt32:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, 0xd2), uint32(i))
+	default:
+		e.buf = binary.BigEndian.AppendUint64(append(e.buf, 0xd3), uint64(i))
+	}
+}
+
+func (e *encoder) writeUint(u uint64) {
+	switch {
+	case u < 128:
+		e.buf = append(e.buf, byte(u))
+	case u <= math.MaxUint8:
+		e.buf = append(e.buf, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, 0xcd), uint16(u))
+	case u <= math.MaxUint32:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, 0xce), uint32(u))
+	default:
+		e.buf = binary.BigEndian.AppendUint64(append(e.buf, 0xcf), u)
+	}
+}
+
+func (e *encoder) writeFloat(f float64) {
+	e.buf = binary.BigEndian.AppendUint64(append(e.buf, 0xcb), math.Float64bits(f))
+}
+
+func (e *encoder) writeString(s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		e.buf = append(e.buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, 0xda), uint16(n))
+	default:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, 0xdb), uint32(n))
+	}
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) writeArrayHeader(n int) {
+	switch {
+	case n < 16:
+		e.buf = append(e.buf, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, 0xdc), uint16(n))
+	default:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, 0xdd), uint32(n))
+	}
+}
+
+func (e *encoder) writeMapHeader(n int) {
+	switch {
+	case n < 16:
+		e.buf = append(e.buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, 0xde), uint16(n))
+	default:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, 0xdf), uint32(n))
+	}
+}
+
+// writeEventTime writes t as the forward protocol's EventTime extension,
+// which keeps nanoseconds
+func (e *encoder) writeEventTime(t time.Time) {
+	e.buf = append(e.buf, 0xd7, 0x00)
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(t.Unix()))
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(t.Nanosecond()))
+}
+
+// writeValue writes a field value, using the message of errors and the %v
+// form of values that aren't strings, booleans or numbers
+func (e *encoder) writeValue(v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		e.writeNil()
+	case string:
+		e.writeString(v)
+	case bool:
+		e.writeBool(v)
+	case int:
+		e.writeInt(int64(v))
+	case int8:
+		e.writeInt(int64(v))
+	case int16:
+		e.writeInt(int64(v))
+	case int32:
+		e.writeInt(int64(v))
+	case int64:
+		e.writeInt(v)
+	case uint:
+		e.writeUint(uint64(v))
+	case uint8:
+		e.writeUint(uint64(v))
+	case uint16:
+		e.writeUint(uint64(v))
+	case uint32:
+		e.writeUint(uint64(v))
+	case uint64:
+		e.writeUint(v)
+	case float32:
+		e.writeFloat(float64(v))
+	case float64:
+		e.writeFloat(v)
+	case error:
+		e.writeString(v.Error())
+	default:
+		e.writeString(fmt.Sprint(v))
+	}
+}
+
+var errUnexpected = errors.New("slfluent: unexpected ack response")
+
+// readAck reads the server's {"ack": chunk} response and returns chunk.
+// Other keys are skipped, as long as their values are strings
+func readAck(r io.Reader) (string, error) {
+	n, err := readMapHeader(r)
+	if err != nil {
+		return "", err
+	}
+	var ack string
+	for i := 0; i < n; i++ {
+		key, err := readString(r)
+		if err != nil {
+			return "", err
+		}
+		value, err := readString(r)
+		if err != nil {
+			return "", err
+		}
+		if key == "ack" {
+			ack = value
+		}
+	}
+	return ack, nil
+}
+
+func readString(r io.Reader) (string, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return "", err
+	}
+	var n int
+	switch {
+	case b[0]&0xe0 == 0xa0:
+		n = int(b[0] & 0x1f)
+	case b[0] == 0xd9, b[0] == 0xda, b[0] == 0xdb:
+		size := 1 << (b[0] - 0xd9)
+		l := make([]byte, size)
+		if _, err := io.ReadFull(r, l); err != nil {
+			return "", err
+		}
+		for _, c := range l {
+			n = n<<8 | int(c)
+		}
+	default:
+		return "", errUnexpected
+	}
+	s := make([]byte, n)
+	if _, err := io.ReadFull(r, s); err != nil {
+		return "", err
+	}
+	return string(s), nil
+}
+
+// readMapHeader reads a map header and returns the number of pairs
+func readMapHeader(r io.Reader) (int, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+	var size int
+	switch {
+	case b[0]&0xf0 == 0x80:
+		return int(b[0] & 0x0f), nil
+	case b[0] == 0xde:
+		size = 2
+	case b[0] == 0xdf:
+		size = 4
+	default:
+		return 0, errUnexpected
+	}
+	l := make([]byte, size)
+	if _, err := io.ReadFull(r, l); err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, c := range l {
+		n = n<<8 | int(c)
+	}
+	return n, nil
+}
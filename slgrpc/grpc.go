@@ -0,0 +1,110 @@
+// Package slgrpc logs calls served by gRPC with simplelog, the way simplelog's
+// Gin middleware logs HTTP requests:
+//
+//	[2024-01-02 10:00:00] INFO grpc.go:96: RPC: /users.Users/Get OK 10.0.0.7 1.2ms
+package slgrpc
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/base-go/simplelog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Option configures the interceptors
+type Option func(*config)
+
+type config struct {
+	codeLevel func(code codes.Code) simplelog.LogLevel
+}
+
+// WithCodeLevel sets the function choosing the level a call is logged at from
+// its status code. The default is DefaultCodeLevel
+func WithCodeLevel(fn func(code codes.Code) simplelog.LogLevel) Option {
+	return func(cfg *config) {
+		cfg.codeLevel = fn
+	}
+}
+
+// DefaultCodeLevel logs codes pointing at a server fault at ERROR, those
+// usually caused by the client or load at WARN, and OK and cancellations at
+// INFO, much as DefaultStatusLevel treats 5xx and 4xx statuses
+func DefaultCodeLevel(code codes.Code) simplelog.LogLevel {
+	switch code {
+	case codes.OK, codes.Canceled:
+		return simplelog.INFO
+	case codes.Unknown, codes.Unimplemented, codes.Internal, codes.DataLoss:
+		return simplelog.ERROR
+	default:
+		return simplelog.WARN
+	}
+}
+
+func newConfig(opts []Option) config {
+	cfg := config{codeLevel: DefaultCodeLevel}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// UnaryServerInterceptor returns an interceptor logging each unary call's
+// method, status code, peer address and latency to l
+func UnaryServerInterceptor(l *simplelog.Logger, opts ...Option) grpc.UnaryServerInterceptor {
+	cfg := newConfig(opts)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		cfg.log(ctx, l, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns an interceptor logging each streaming
+// call's method, status code, peer address and duration to l once it ends
+func StreamServerInterceptor(l *simplelog.Logger, opts ...Option) grpc.StreamServerInterceptor {
+	cfg := newConfig(opts)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		cfg.log(ss.Context(), l, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// log logs a finished call. The status message of a failed call follows
+// its latency
+func (cfg config) log(ctx context.Context, l *simplelog.Logger, method string, start time.Time, err error) {
+	latency := time.Since(start)
+	st := status.Convert(err)
+
+	format := "RPC: %s %s %s %s"
+	args := []interface{}{method, st.Code(), peerAddr(ctx), latency}
+	if err != nil {
+		format += " %s"
+		args = append(args, st.Message())
+	}
+	// Fields the context extractors find, such as trace IDs, come from the
+	// call's context
+	l.Ctx(ctx).Log(cfg.codeLevel(st.Code()), format, args...)
+}
+
+// peerAddr returns the IP of the call's client, or "unknown"
+func peerAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
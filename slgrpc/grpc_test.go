@@ -0,0 +1,42 @@
+package slgrpc
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/base-go/simplelog"
+	"google.golang.org/grpc"
+)
+
+type traceKey struct{}
+
+func TestInterceptorsUseCallContext(t *testing.T) {
+	l := simplelog.NewWriter(simplelog.INFO, io.Discard)
+	l.AddContextExtractor(simplelog.ContextValue(traceKey{}, "trace_id"))
+	var fields []simplelog.Field
+	l.AddHook(func(entry simplelog.Entry) { fields = entry.Fields })
+	ctx := context.WithValue(context.Background(), traceKey{}, "abc123")
+
+	unary := UnaryServerInterceptor(l)
+	unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/users.Users/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil })
+	if len(fields) != 1 || fields[0].Key != "trace_id" || fields[0].Value != "abc123" {
+		t.Errorf("unary: got fields %v, want trace_id from the call context", fields)
+	}
+
+	fields = nil
+	stream := StreamServerInterceptor(l)
+	stream(nil, fakeStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/users.Users/List"},
+		func(srv interface{}, ss grpc.ServerStream) error { return nil })
+	if len(fields) != 1 || fields[0].Key != "trace_id" || fields[0].Value != "abc123" {
+		t.Errorf("stream: got fields %v, want trace_id from the call context", fields)
+	}
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s fakeStream) Context() context.Context { return s.ctx }
@@ -0,0 +1,171 @@
+// Package slkafka publishes simplelog entries to a Kafka topic
+package slkafka
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/base-go/simplelog"
+	"github.com/base-go/simplelog/internal/batch"
+	"github.com/segmentio/kafka-go"
+)
+
+// Writer is the part of the Kafka producer used by Sink; *kafka.Writer
+// implements it
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// Sink publishes entries to Kafka in batches from a background goroutine.
+// Failed batches are resent whole, so delivery is at least once. Entries
+// that can't be queued or delivered are dropped and counted
+type Sink struct {
+	*batch.Queue
+
+	w         Writer
+	topic     string
+	formatter simplelog.Formatter
+	key       func(entry simplelog.Entry) []byte
+	timeout   time.Duration
+}
+
+// Option configures a Sink
+type Option func(*sinkConfig)
+
+type sinkConfig struct {
+	batch     batch.Config
+	formatter simplelog.Formatter
+	key       func(entry simplelog.Entry) []byte
+}
+
+// WithFlushInterval sets how often queued entries are published. The default is 1 second
+func WithFlushInterval(d time.Duration) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.FlushInterval = d
+	}
+}
+
+// WithBatchSize sets the most entries published in one call. The default is 100
+func WithBatchSize(n int) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.BatchSize = n
+	}
+}
+
+// WithQueueSize sets how many entries may wait to be published before new
+// ones are dropped. The default is 10000
+func WithQueueSize(n int) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.QueueSize = n
+	}
+}
+
+// WithRetryPolicy sets how failed batches are retried before being dropped.
+// The default is simplelog.DefaultRetryPolicy
+func WithRetryPolicy(p simplelog.RetryPolicy) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.Retry = p
+	}
+}
+
+// WithDropHandler sets a function called with the error and number of
+// entries when a batch is dropped after its retries, e.g. to alert or to
+// write the failure to a local log
+func WithDropHandler(fn func(err error, n int)) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.OnDrop = fn
+	}
+}
+
+// WithFormatter sets the formatter rendering each message value. The
+// default is simplelog.JSONFormatter
+func WithFormatter(f simplelog.Formatter) Option {
+	return func(cfg *sinkConfig) {
+		cfg.formatter = f
+	}
+}
+
+// WithKey sets the function choosing each message's key, and so its
+// partition. Messages have no key by default
+func WithKey(fn func(entry simplelog.Entry) []byte) Option {
+	return func(cfg *sinkConfig) {
+		cfg.key = fn
+	}
+}
+
+// KeyByLevel keys messages by the entry's level name
+func KeyByLevel(entry simplelog.Entry) []byte {
+	return []byte(entry.Level.String())
+}
+
+// KeyByLogger keys messages by the entry's logger name, see Logger.Named
+func KeyByLogger(entry simplelog.Entry) []byte {
+	return []byte(entry.Logger)
+}
+
+// KeyByField keys messages by the value of the named field, e.g. a global
+// "service" field, leaving entries without it unkeyed
+func KeyByField(name string) func(entry simplelog.Entry) []byte {
+	return func(entry simplelog.Entry) []byte {
+		for _, field := range entry.Fields {
+			if field.Key == name {
+				return []byte(fmt.Sprint(field.Value))
+			}
+		}
+		return nil
+	}
+}
+
+// New creates a Sink publishing to topic through w. If w is a *kafka.Writer
+// with its Topic set, topic must be ""; a *kafka.Writer should have a short
+// BatchTimeout, as the Sink does its own batching. Register the Sink with
+// Logger.AddSink and Close it on shutdown, before closing w
+func New(w Writer, topic string, opts ...Option) *Sink {
+	cfg := sinkConfig{
+		batch: batch.Config{
+			Name:          "slkafka",
+			QueueSize:     10000,
+			BatchSize:     100,
+			FlushInterval: time.Second,
+			Retry:         simplelog.DefaultRetryPolicy,
+		},
+		formatter: &simplelog.JSONFormatter{},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	s := &Sink{
+		w:         w,
+		topic:     topic,
+		formatter: cfg.formatter,
+		key:       cfg.key,
+		timeout:   30 * time.Second,
+	}
+	s.Queue = batch.New(cfg.batch, s.send)
+	return s
+}
+
+// send publishes entries. Entries the formatter fails on are left out
+func (s *Sink) send(entries []simplelog.Entry) error {
+	msgs := make([]kafka.Message, 0, len(entries))
+	for _, entry := range entries {
+		value, err := s.formatter.Format(entry)
+		if err != nil {
+			continue
+		}
+		msg := kafka.Message{Topic: s.topic, Value: value, Time: entry.Time}
+		if s.key != nil {
+			msg.Key = s.key(entry)
+		}
+		msgs = append(msgs, msg)
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	return s.w.WriteMessages(ctx, msgs...)
+}
@@ -0,0 +1,78 @@
+package slkafka
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/base-go/simplelog"
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeWriter records the messages published, failing the first fail calls
+type fakeWriter struct {
+	mu    sync.Mutex
+	fail  int
+	calls int
+	msgs  []kafka.Message
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.calls++; w.calls <= w.fail {
+		return errors.New("leader not available")
+	}
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func TestSinkPublishesKeyedMessages(t *testing.T) {
+	w := &fakeWriter{}
+	s := New(w, "logs", WithKey(KeyByField("service")), WithFlushInterval(time.Hour))
+	at := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	s.WriteEntry(simplelog.Entry{Time: at, Level: simplelog.INFO, Message: "started",
+		Fields: []simplelog.Field{{Key: "id", Value: 7}, {Key: "service", Value: "billing"}}})
+	s.WriteEntry(simplelog.Entry{Time: at, Level: simplelog.WARN, Message: "no service field"})
+	s.Close()
+
+	if len(w.msgs) != 2 {
+		t.Fatalf("published %d messages, want 2", len(w.msgs))
+	}
+	m := w.msgs[0]
+	if m.Topic != "logs" || string(m.Key) != "billing" || !m.Time.Equal(at) {
+		t.Errorf("got topic %q key %q time %v", m.Topic, m.Key, m.Time)
+	}
+	if !strings.Contains(string(m.Value), `"message":"started"`) {
+		t.Errorf("value %s isn't the JSON entry", m.Value)
+	}
+	if w.msgs[1].Key != nil {
+		t.Errorf("entry without the field keyed %q, want no key", w.msgs[1].Key)
+	}
+}
+
+func TestSinkRetriesFailedBatch(t *testing.T) {
+	w := &fakeWriter{fail: 2}
+	s := New(w, "logs", WithFlushInterval(time.Hour),
+		WithRetryPolicy(simplelog.RetryPolicy{MaxAttempts: 3, Sleep: func(time.Duration) {}}))
+	s.WriteEntry(simplelog.Entry{Level: simplelog.INFO, Message: "once"})
+	s.Close()
+
+	if w.calls != 3 || len(w.msgs) != 1 || s.Dropped() != 0 {
+		t.Errorf("got %d calls, %d messages and %d dropped, want 3, 1 and 0", w.calls, len(w.msgs), s.Dropped())
+	}
+}
+
+func TestKeyByLevelAndLogger(t *testing.T) {
+	entry := simplelog.Entry{Level: simplelog.ERROR, Logger: "db"}
+	if got := string(KeyByLevel(entry)); got != simplelog.ERROR.String() {
+		t.Errorf("KeyByLevel = %q", got)
+	}
+	if got := string(KeyByLogger(entry)); got != "db" {
+		t.Errorf("KeyByLogger = %q", got)
+	}
+}
@@ -0,0 +1,83 @@
+// Package sllogr adapts simplelog to logr, so that libraries taking a
+// logr.Logger, such as controller-runtime, log through simplelog
+package sllogr
+
+import (
+	"github.com/base-go/simplelog"
+	"github.com/go-logr/logr"
+)
+
+// NewLogger returns a logr.Logger logging to l
+func NewLogger(l *simplelog.Logger) logr.Logger {
+	return logr.New(&Sink{l: l})
+}
+
+// Sink is a logr.LogSink writing to a simplelog.Logger. logr's V-levels map
+// to INFO for 0, DEBUG for 1 and TRACE for 2 and above
+type Sink struct {
+	l *simplelog.Logger
+	// depth is the number of frames between the code logging and the Sink
+	depth int
+}
+
+var (
+	_ logr.LogSink          = (*Sink)(nil)
+	_ logr.CallDepthLogSink = (*Sink)(nil)
+)
+
+// NewSink returns a Sink logging to l
+func NewSink(l *simplelog.Logger) *Sink {
+	return &Sink{l: l}
+}
+
+// Level returns the simplelog level of logr V-level v
+func Level(v int) simplelog.LogLevel {
+	switch {
+	case v <= 0:
+		return simplelog.INFO
+	case v == 1:
+		return simplelog.DEBUG
+	default:
+		return simplelog.TRACE
+	}
+}
+
+func (s *Sink) Init(info logr.RuntimeInfo) {
+	s.depth = info.CallDepth
+}
+
+func (s *Sink) Enabled(level int) bool {
+	return s.l.Enabled(Level(level))
+}
+
+func (s *Sink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.log(Level(level), msg, keysAndValues)
+}
+
+func (s *Sink) Error(err error, msg string, keysAndValues ...interface{}) {
+	if err != nil {
+		keysAndValues = append([]interface{}{"error", err}, keysAndValues...)
+	}
+	s.log(simplelog.ERROR, msg, keysAndValues)
+}
+
+// log logs msg, reporting the caller of the logr.Logger method
+func (s *Sink) log(level simplelog.LogLevel, msg string, keysAndValues []interface{}) {
+	l := s.l
+	if len(keysAndValues) > 0 {
+		l = l.With(keysAndValues...)
+	}
+	l.WithCallerSkip(s.depth+2).Log(level, "%s", msg)
+}
+
+func (s *Sink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &Sink{l: s.l.With(keysAndValues...), depth: s.depth}
+}
+
+func (s *Sink) WithName(name string) logr.LogSink {
+	return &Sink{l: s.l.Named(name), depth: s.depth}
+}
+
+func (s *Sink) WithCallDepth(depth int) logr.LogSink {
+	return &Sink{l: s.l, depth: s.depth + depth}
+}
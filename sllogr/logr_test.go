@@ -0,0 +1,64 @@
+package sllogr
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/base-go/simplelog"
+	"github.com/go-logr/logr"
+)
+
+func newTestLogger() (logr.Logger, *simplelog.Entry) {
+	l := simplelog.NewWriter(simplelog.TRACE, io.Discard)
+	var last simplelog.Entry
+	l.AddHook(func(entry simplelog.Entry) { last = entry })
+	return NewLogger(l), &last
+}
+
+// line returns the line it is called from
+func line() int {
+	_, _, n, _ := runtime.Caller(1)
+	return n
+}
+
+func checkCaller(t *testing.T, entry *simplelog.Entry, want int) {
+	t.Helper()
+	if filepath.Base(entry.File) != "logr_test.go" || entry.Line != want {
+		t.Errorf("caller %s:%d, want logr_test.go:%d", entry.File, entry.Line, want)
+	}
+}
+
+func TestCallerIsLogrCall(t *testing.T) {
+	log, last := newTestLogger()
+
+	want := line() + 1
+	log.Info("info")
+	checkCaller(t, last, want)
+
+	want = line() + 1
+	log.WithValues("k", "v").V(1).Info("debug")
+	checkCaller(t, last, want)
+	if last.Level != simplelog.DEBUG {
+		t.Errorf("V(1) logged at %v, want DEBUG", last.Level)
+	}
+
+	want = line() + 1
+	log.WithName("sub").Error(errors.New("boom"), "failed")
+	checkCaller(t, last, want)
+}
+
+// helper logs on behalf of its caller, as logr helpers marked with
+// WithCallDepth do
+func helper(log logr.Logger) {
+	log.WithCallDepth(1).Info("from helper")
+}
+
+func TestCallerWithCallDepth(t *testing.T) {
+	log, last := newTestLogger()
+	want := line() + 1
+	helper(log)
+	checkCaller(t, last, want)
+}
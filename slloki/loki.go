@@ -0,0 +1,256 @@
+// Package slloki pushes simplelog entries to Grafana Loki
+package slloki
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/base-go/simplelog"
+	"github.com/base-go/simplelog/internal/batch"
+)
+
+// Sink pushes entries to Loki's push API in batches from a background
+// goroutine. Entries that can't be queued or delivered are dropped and counted
+type Sink struct {
+	*batch.Queue
+
+	url         string
+	client      *http.Client
+	header      http.Header
+	formatter   simplelog.Formatter
+	labels      map[string]string
+	levelLabel  bool
+	fieldLabels []string
+}
+
+// Option configures a Sink
+type Option func(*sinkConfig)
+
+type sinkConfig struct {
+	batch     batch.Config
+	client    *http.Client
+	header    http.Header
+	formatter simplelog.Formatter
+	labels    map[string]string
+	level     bool
+	fields    []string
+}
+
+// WithFlushInterval sets how often queued entries are pushed. The default is 1 second
+func WithFlushInterval(d time.Duration) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.FlushInterval = d
+	}
+}
+
+// WithBatchSize sets the most entries pushed in one request. The default is 1000
+func WithBatchSize(n int) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.BatchSize = n
+	}
+}
+
+// WithQueueSize sets how many entries may wait to be pushed before new ones
+// are dropped. The default is 10000
+func WithQueueSize(n int) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.QueueSize = n
+	}
+}
+
+// WithRetryPolicy sets how failed batches are retried before being dropped.
+// The default is simplelog.DefaultRetryPolicy
+func WithRetryPolicy(p simplelog.RetryPolicy) Option {
+	return func(cfg *sinkConfig) {
+		cfg.batch.Retry = p
+	}
+}
+
+// WithHTTPClient sets the client requests are sent with
+func WithHTTPClient(c *http.Client) Option {
+	return func(cfg *sinkConfig) {
+		cfg.client = c
+	}
+}
+
+// WithBasicAuth authenticates requests, e.g. with a Grafana Cloud user and
+// API token
+func WithBasicAuth(user, password string) Option {
+	return func(cfg *sinkConfig) {
+		cfg.header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user+":"+password)))
+	}
+}
+
+// WithTenant sets the tenant of a multi-tenant Loki
+func WithTenant(id string) Option {
+	return func(cfg *sinkConfig) {
+		cfg.header.Set("X-Scope-OrgID", id)
+	}
+}
+
+// WithFormatter sets the formatter rendering each log line. The default is
+// simplelog.LogfmtFormatter, which LogQL's logfmt parser reads
+func WithFormatter(f simplelog.Formatter) Option {
+	return func(cfg *sinkConfig) {
+		cfg.formatter = f
+	}
+}
+
+// WithLabel adds a label with a fixed value to every stream, e.g. "service"
+func WithLabel(name, value string) Option {
+	return func(cfg *sinkConfig) {
+		cfg.labels[name] = value
+	}
+}
+
+// WithHostLabel adds a host label holding os.Hostname()
+func WithHostLabel() Option {
+	host, _ := os.Hostname()
+	return WithLabel("host", host)
+}
+
+// WithLevelLabel adds a level label holding each entry's level in lower case
+func WithLevelLabel() Option {
+	return func(cfg *sinkConfig) {
+		cfg.level = true
+	}
+}
+
+// WithFieldLabels turns the named fields into labels of the entries that
+// have them. Every distinct value makes a stream, so use them only for
+// fields with a few values
+func WithFieldLabels(names ...string) Option {
+	return func(cfg *sinkConfig) {
+		cfg.fields = append(cfg.fields, names...)
+	}
+}
+
+// New creates a Sink pushing to the Loki at url, e.g.
+// "http://localhost:3100". Register it with Logger.AddSink and Close it on
+// shutdown. Loki rejects streams without labels, so unless a fixed or level
+// label is given, streams get a service_name label holding the program name
+func New(url string, opts ...Option) *Sink {
+	cfg := sinkConfig{
+		batch: batch.Config{
+			Name:          "slloki",
+			QueueSize:     10000,
+			BatchSize:     1000,
+			FlushInterval: time.Second,
+			Retry:         simplelog.DefaultRetryPolicy,
+		},
+		client:    &http.Client{Timeout: 30 * time.Second},
+		header:    http.Header{"Content-Type": {"application/json"}},
+		formatter: &simplelog.LogfmtFormatter{},
+		labels:    map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if len(cfg.labels) == 0 && !cfg.level {
+		cfg.labels["service_name"] = filepath.Base(os.Args[0])
+	}
+
+	s := &Sink{
+		url:         strings.TrimSuffix(url, "/") + "/loki/api/v1/push",
+		client:      cfg.client,
+		header:      cfg.header,
+		formatter:   cfg.formatter,
+		labels:      cfg.labels,
+		levelLabel:  cfg.level,
+		fieldLabels: cfg.fields,
+	}
+	s.Queue = batch.New(cfg.batch, s.send)
+	return s
+}
+
+type stream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// send pushes entries, grouped into streams by their labels
+func (s *Sink) send(entries []simplelog.Entry) error {
+	var streams []*stream
+	index := map[string]*stream{}
+	for _, entry := range entries {
+		line, err := s.formatter.Format(entry)
+		if err != nil {
+			continue
+		}
+		labels := s.streamLabels(entry)
+		key := labelKey(labels)
+		st, ok := index[key]
+		if !ok {
+			st = &stream{Stream: labels}
+			index[key] = st
+			streams = append(streams, st)
+		}
+		st.Values = append(st.Values, [2]string{
+			strconv.FormatInt(entry.Time.UnixNano(), 10),
+			strings.TrimSuffix(string(line), "\n"),
+		})
+	}
+	if len(streams) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string][]*stream{"streams": streams})
+	if err != nil {
+		return simplelog.Permanent(err)
+	}
+	return batch.Post(s.client, s.url, s.header, bytes.NewReader(body))
+}
+
+// streamLabels returns the labels of entry's stream
+func (s *Sink) streamLabels(entry simplelog.Entry) map[string]string {
+	labels := make(map[string]string, len(s.labels)+1+len(s.fieldLabels))
+	for k, v := range s.labels {
+		labels[k] = v
+	}
+	if s.levelLabel {
+		labels["level"] = strings.ToLower(entry.Level.String())
+	}
+	for _, name := range s.fieldLabels {
+		for _, field := range entry.Fields {
+			if field.Key == name {
+				labels[labelName(name)] = fmt.Sprint(field.Value)
+			}
+		}
+	}
+	return labels
+}
+
+// labelKey returns a string identifying a label set
+func labelKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(strconv.Quote(name))
+		b.WriteString(strconv.Quote(labels[name]))
+	}
+	return b.String()
+}
+
+// labelName replaces the characters a Prometheus label name can't contain
+func labelName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
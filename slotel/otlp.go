@@ -0,0 +1,292 @@
+package slotel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/base-go/simplelog"
+	"github.com/base-go/simplelog/internal/batch"
+)
+
+// Exporter is a simplelog.Sink sending entries to an OpenTelemetry collector
+// over OTLP/HTTP with JSON encoding, in batches from a background goroutine.
+// Entries that can't be queued or delivered are dropped and counted
+type Exporter struct {
+	*batch.Queue
+
+	url      string
+	client   *http.Client
+	header   http.Header
+	resource []keyValue
+}
+
+// Option configures an Exporter
+type Option func(*exporterConfig)
+
+type exporterConfig struct {
+	batch    batch.Config
+	client   *http.Client
+	header   http.Header
+	resource map[string]string
+}
+
+// WithFlushInterval sets how often queued entries are sent. The default is 5 seconds
+func WithFlushInterval(d time.Duration) Option {
+	return func(cfg *exporterConfig) {
+		cfg.batch.FlushInterval = d
+	}
+}
+
+// WithBatchSize sets the most entries sent in one request. The default is 512
+func WithBatchSize(n int) Option {
+	return func(cfg *exporterConfig) {
+		cfg.batch.BatchSize = n
+	}
+}
+
+// WithQueueSize sets how many entries may wait to be sent before new ones are
+// dropped. The default is 10000
+func WithQueueSize(n int) Option {
+	return func(cfg *exporterConfig) {
+		cfg.batch.QueueSize = n
+	}
+}
+
+// WithRetryPolicy sets how failed batches are retried before being dropped.
+// The default is simplelog.DefaultRetryPolicy
+func WithRetryPolicy(p simplelog.RetryPolicy) Option {
+	return func(cfg *exporterConfig) {
+		cfg.batch.Retry = p
+	}
+}
+
+// WithHTTPClient sets the client requests are sent with
+func WithHTTPClient(c *http.Client) Option {
+	return func(cfg *exporterConfig) {
+		cfg.client = c
+	}
+}
+
+// WithHeader adds a header to every request, e.g. for authentication
+func WithHeader(key, value string) Option {
+	return func(cfg *exporterConfig) {
+		cfg.header.Add(key, value)
+	}
+}
+
+// WithResource sets a resource attribute, such as "service.name", describing
+// the process the entries come from
+func WithResource(key, value string) Option {
+	return func(cfg *exporterConfig) {
+		cfg.resource[key] = value
+	}
+}
+
+// NewExporter creates an Exporter posting to the collector at endpoint, e.g.
+// "http://localhost:4318"; "/v1/logs" is added unless the endpoint has a
+// path. Register it with Logger.AddSink and Close it on shutdown. Only
+// OTLP/HTTP is supported; gRPC collectors usually accept it on port 4318 too
+func NewExporter(endpoint string, opts ...Option) *Exporter {
+	cfg := exporterConfig{
+		batch: batch.Config{
+			Name:          "slotel",
+			QueueSize:     10000,
+			BatchSize:     512,
+			FlushInterval: 5 * time.Second,
+			Retry:         simplelog.DefaultRetryPolicy,
+		},
+		client:   &http.Client{Timeout: 30 * time.Second},
+		header:   http.Header{"Content-Type": {"application/json"}},
+		resource: map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	url := strings.TrimSuffix(endpoint, "/")
+	if i := strings.Index(url, "://"); i < 0 || !strings.Contains(url[i+3:], "/") {
+		url += "/v1/logs"
+	}
+	e := &Exporter{url: url, client: cfg.client, header: cfg.header}
+	for k, v := range cfg.resource {
+		e.resource = append(e.resource, keyValue{Key: k, Value: anyValue(v)})
+	}
+	e.Queue = batch.New(cfg.batch, e.send)
+	return e
+}
+
+// The OTLP/JSON encoding of ExportLogsServiceRequest, see
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type (
+	exportRequest struct {
+		ResourceLogs []resourceLogs `json:"resourceLogs"`
+	}
+	resourceLogs struct {
+		Resource  resource    `json:"resource"`
+		ScopeLogs []scopeLogs `json:"scopeLogs"`
+	}
+	resource struct {
+		Attributes []keyValue `json:"attributes,omitempty"`
+	}
+	scopeLogs struct {
+		Scope      scope       `json:"scope"`
+		LogRecords []logRecord `json:"logRecords"`
+	}
+	scope struct {
+		Name string `json:"name"`
+	}
+	logRecord struct {
+		TimeUnixNano         string     `json:"timeUnixNano"`
+		ObservedTimeUnixNano string     `json:"observedTimeUnixNano"`
+		SeverityNumber       int        `json:"severityNumber"`
+		SeverityText         string     `json:"severityText"`
+		Body                 value      `json:"body"`
+		Attributes           []keyValue `json:"attributes,omitempty"`
+		TraceID              string     `json:"traceId,omitempty"`
+		SpanID               string     `json:"spanId,omitempty"`
+	}
+	keyValue struct {
+		Key   string `json:"key"`
+		Value value  `json:"value"`
+	}
+	value struct {
+		StringValue *string `json:"stringValue,omitempty"`
+		BoolValue   *bool   `json:"boolValue,omitempty"`
+		IntValue    *string `json:"intValue,omitempty"`
+		DoubleValue *double `json:"doubleValue,omitempty"`
+	}
+)
+
+// double is a float64 encoded the way the protobuf JSON mapping expects, with
+// NaN and the infinities as strings, which encoding/json can't marshal
+type double float64
+
+func (d double) MarshalJSON() ([]byte, error) {
+	f := float64(d)
+	switch {
+	case math.IsNaN(f):
+		return []byte(`"NaN"`), nil
+	case math.IsInf(f, 1):
+		return []byte(`"Infinity"`), nil
+	case math.IsInf(f, -1):
+		return []byte(`"-Infinity"`), nil
+	}
+	return json.Marshal(f)
+}
+
+// send posts entries as one export request
+func (e *Exporter) send(entries []simplelog.Entry) error {
+	// Entries are grouped by logger name, which becomes the scope name
+	var scopes []scopeLogs
+	index := map[string]int{}
+	now := strconv.FormatInt(time.Now().UnixNano(), 10)
+	for _, entry := range entries {
+		name := entry.Logger
+		if name == "" {
+			name = "github.com/base-go/simplelog"
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(scopes)
+			index[name] = i
+			scopes = append(scopes, scopeLogs{Scope: scope{Name: name}})
+		}
+		scopes[i].LogRecords = append(scopes[i].LogRecords, record(entry, now))
+	}
+
+	body, err := json.Marshal(exportRequest{ResourceLogs: []resourceLogs{{
+		Resource:  resource{Attributes: e.resource},
+		ScopeLogs: scopes,
+	}}})
+	if err != nil {
+		return simplelog.Permanent(err)
+	}
+	return batch.Post(e.client, e.url, e.header, bytes.NewReader(body))
+}
+
+// record converts entry to a log record. The trace_id and span_id fields
+// added by TraceFields become the record's trace context
+func record(entry simplelog.Entry, observed string) logRecord {
+	r := logRecord{
+		TimeUnixNano:         strconv.FormatInt(entry.Time.UnixNano(), 10),
+		ObservedTimeUnixNano: observed,
+		SeverityNumber:       severity(entry.Level),
+		SeverityText:         entry.Level.String(),
+		Body:                 anyValue(entry.Message),
+	}
+	if entry.File != "" {
+		r.Attributes = append(r.Attributes,
+			keyValue{Key: "code.filepath", Value: anyValue(entry.File)},
+			keyValue{Key: "code.lineno", Value: anyValue(entry.Line)})
+	}
+	if entry.Function != "" {
+		r.Attributes = append(r.Attributes, keyValue{Key: "code.function", Value: anyValue(entry.Function)})
+	}
+	for _, field := range entry.Fields {
+		switch s, _ := field.Value.(string); {
+		case field.Key == "trace_id" && s != "":
+			r.TraceID = s
+		case field.Key == "span_id" && s != "":
+			r.SpanID = s
+		default:
+			r.Attributes = append(r.Attributes, keyValue{Key: field.Key, Value: anyValue(field.Value)})
+		}
+	}
+	if entry.Stack != "" {
+		r.Attributes = append(r.Attributes, keyValue{Key: "exception.stacktrace", Value: anyValue(entry.Stack)})
+	}
+	return r
+}
+
+// severity maps a level to its OTLP severity number, PANIC being FATAL and
+// FATAL FATAL2. Custom levels take the number of the built-in level below them
+func severity(level simplelog.LogLevel) int {
+	switch {
+	case level < simplelog.DEBUG:
+		return 1
+	case level < simplelog.INFO:
+		return 5
+	case level < simplelog.WARN:
+		return 9
+	case level < simplelog.ERROR:
+		return 13
+	case level < simplelog.PANIC:
+		return 17
+	case level < simplelog.FATAL:
+		return 21
+	default:
+		return 22
+	}
+}
+
+// anyValue converts v to an OTLP AnyValue, using the %v form of values that
+// aren't strings, booleans or numbers
+func anyValue(v interface{}) value {
+	switch v := v.(type) {
+	case string:
+		return value{StringValue: &v}
+	case bool:
+		return value{BoolValue: &v}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32:
+		s := fmt.Sprint(v)
+		return value{IntValue: &s}
+	case float32:
+		f := double(v)
+		return value{DoubleValue: &f}
+	case float64:
+		f := double(v)
+		return value{DoubleValue: &f}
+	case error:
+		s := v.Error()
+		return value{StringValue: &s}
+	default:
+		s := fmt.Sprint(v)
+		return value{StringValue: &s}
+	}
+}
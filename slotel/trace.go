@@ -0,0 +1,30 @@
+// Package slotel connects simplelog to OpenTelemetry
+package slotel
+
+import (
+	"context"
+
+	"github.com/base-go/simplelog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceFields is a simplelog.ContextExtractor adding the trace_id and span_id
+// of the span in ctx, if any, so that logs can be matched to traces in tools
+// such as Grafana Tempo
+func TraceFields(ctx context.Context) []simplelog.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []simplelog.Field{
+		{Key: "trace_id", Value: sc.TraceID().String()},
+		{Key: "span_id", Value: sc.SpanID().String()},
+	}
+}
+
+// EnableTraceFields adds TraceFields to l's context extractors, so entries
+// logged with l.Ctx(ctx) or simplelog.FromContext(ctx), and the access log
+// entries of the HTTP middlewares, carry the IDs of the current span
+func EnableTraceFields(l *simplelog.Logger) {
+	l.AddContextExtractor(TraceFields)
+}
@@ -0,0 +1,139 @@
+// Package slsentry forwards simplelog entries to Sentry
+package slsentry
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/base-go/simplelog"
+	"github.com/getsentry/sentry-go"
+)
+
+// Option configures the hook returned by Hook
+type Option func(*config)
+
+type config struct {
+	hub   *sentry.Hub
+	level simplelog.LogLevel
+	flush time.Duration
+}
+
+// WithHub sets the hub events are captured with. The default is
+// sentry.CurrentHub(), set up by sentry.Init
+func WithHub(hub *sentry.Hub) Option {
+	return func(cfg *config) {
+		cfg.hub = hub
+	}
+}
+
+// WithLevel sets the lowest level forwarded. The default is ERROR
+func WithLevel(level simplelog.LogLevel) Option {
+	return func(cfg *config) {
+		cfg.level = level
+	}
+}
+
+// WithFlushTimeout sets how long PANIC and FATAL entries wait for Sentry to
+// receive them before the program goes on to panic or exit. The default is
+// 2 seconds
+func WithFlushTimeout(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.flush = d
+	}
+}
+
+// Hook returns a hook, for Logger.AddHook, capturing entries at ERROR and
+// above as Sentry events with their message, fields and stack:
+//
+//	sentry.Init(sentry.ClientOptions{Dsn: dsn})
+//	l.AddHook(slsentry.Hook())
+//
+// The first error among an entry's fields is reported as the event's exception
+func Hook(opts ...Option) simplelog.Hook {
+	cfg := config{level: simplelog.ERROR, flush: 2 * time.Second}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(entry simplelog.Entry) {
+		if entry.Level < cfg.level {
+			return
+		}
+		hub := cfg.hub
+		if hub == nil {
+			hub = sentry.CurrentHub()
+		}
+
+		hub.CaptureEvent(event(entry))
+		if entry.Level >= simplelog.PANIC {
+			hub.Flush(cfg.flush)
+		}
+	}
+}
+
+// event converts entry to a Sentry event
+func event(entry simplelog.Entry) *sentry.Event {
+	ev := sentry.NewEvent()
+	ev.Level = level(entry.Level)
+	ev.Message = entry.Message
+	ev.Timestamp = entry.Time
+	ev.Logger = entry.Logger
+	if entry.File != "" {
+		ev.Tags["caller"] = fmt.Sprintf("%s:%d", entry.File, entry.Line)
+	}
+	if entry.Function != "" {
+		ev.Transaction = entry.Function
+	}
+
+	var reported bool
+	for _, field := range entry.Fields {
+		err, ok := field.Value.(error)
+		if ok && !reported {
+			ev.Exception = exceptions(err)
+			reported = true
+		}
+		if ok {
+			ev.Extra[field.Key] = err.Error()
+		} else {
+			ev.Extra[field.Key] = field.Value
+		}
+	}
+	if entry.Stack != "" {
+		ev.Extra["stack"] = entry.Stack
+	}
+	return ev
+}
+
+// exceptions returns the chain of errors wrapped by err, innermost first as
+// Sentry expects, with the stack trace of those that record one
+func exceptions(err error) []sentry.Exception {
+	var chain []sentry.Exception
+	for ; err != nil && len(chain) < 10; err = errors.Unwrap(err) {
+		chain = append(chain, sentry.Exception{
+			Type:       fmt.Sprintf("%T", err),
+			Value:      err.Error(),
+			Stacktrace: sentry.ExtractStacktrace(err),
+		})
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain
+}
+
+// level maps a LogLevel to a Sentry level
+func level(l simplelog.LogLevel) sentry.Level {
+	switch {
+	case l < simplelog.INFO:
+		return sentry.LevelDebug
+	case l < simplelog.WARN:
+		return sentry.LevelInfo
+	case l < simplelog.ERROR:
+		return sentry.LevelWarning
+	case l < simplelog.PANIC:
+		return sentry.LevelError
+	default:
+		return sentry.LevelFatal
+	}
+}
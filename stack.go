@@ -0,0 +1,123 @@
+package simplelog
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// errorStack returns the stack traces carried by the first error value in
+// fields, as recorded by packages like github.com/pkg/errors. Unless full is
+// set, frames already printed for an inner error are collapsed for the
+// errors wrapping it
+func errorStack(fields []Field, full bool) string {
+	for _, field := range fields {
+		if err, ok := field.Value.(error); ok {
+			return chainStack(err, full)
+		}
+	}
+	return ""
+}
+
+func chainStack(err error, full bool) string {
+	var traces [][]uintptr
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if pcs := stackOf(e); len(pcs) > 0 {
+			traces = append(traces, pcs)
+		}
+	}
+	if len(traces) == 0 {
+		return ""
+	}
+
+	// Print the innermost trace first, as it is closest to the root cause
+	var b strings.Builder
+	seen := make(map[uintptr]bool)
+	for i := len(traces) - 1; i >= 0; i-- {
+		pcs := traces[i]
+		if !full {
+			pcs = unseenFrames(pcs, seen)
+		}
+		if len(pcs) == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("wrapped at:\n")
+		}
+		writeFrames(&b, pcs)
+		if elided := len(traces[i]) - len(pcs); elided > 0 {
+			fmt.Fprintf(&b, "\t... %d frames elided\n", elided)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// SetStackTraces attaches the stack of the logging goroutine, from the caller
+// up, to entries at or above level, e.g. ERROR, alongside any error stacks
+func (l *Logger) SetStackTraces(enabled bool, level LogLevel) {
+	l.stackLevel.Store(int64(level))
+	l.stacks.Store(enabled)
+}
+
+// callerStack returns the stack of the calling goroutine starting skip frames
+// above callerStack's caller, as runtime.Caller counts them
+func callerStack(skip int) string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	if n == 0 {
+		return ""
+	}
+	var b strings.Builder
+	writeFrames(&b, pcs[:n])
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// unseenFrames returns the frames of pcs not yet in seen, and marks all of pcs as seen
+func unseenFrames(pcs []uintptr, seen map[uintptr]bool) []uintptr {
+	var out []uintptr
+	for _, pc := range pcs {
+		if !seen[pc] {
+			out = append(out, pc)
+			seen[pc] = true
+		}
+	}
+	return out
+}
+
+func writeFrames(b *strings.Builder, pcs []uintptr) {
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			return
+		}
+	}
+}
+
+// stackOf returns the program counters recorded by err, if it exposes them
+// through a Callers() []uintptr method or a pkg/errors style StackTrace()
+// method returning a slice of uintptr-based frames
+func stackOf(err error) []uintptr {
+	if c, ok := err.(interface{ Callers() []uintptr }); ok {
+		return c.Callers()
+	}
+
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	out := m.Type().Out(0)
+	if out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	trace := m.Call(nil)[0]
+	pcs := make([]uintptr, trace.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(trace.Index(i).Uint())
+	}
+	return pcs
+}
@@ -0,0 +1,71 @@
+package simplelog
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// tracedError records the stack it was created at, like pkg/errors
+type tracedError struct {
+	msg   string
+	cause error
+	pcs   []uintptr
+}
+
+func (e *tracedError) Error() string      { return e.msg }
+func (e *tracedError) Unwrap() error      { return e.cause }
+func (e *tracedError) Callers() []uintptr { return e.pcs }
+
+func newTracedError(msg string, cause error) error {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	return &tracedError{msg: msg, cause: cause, pcs: pcs[:n]}
+}
+
+// wrapDeep returns an error wrapped depth times, each wrap recorded one
+// frame further up the stack than the error it wraps
+func wrapDeep(depth int) error {
+	if depth == 0 {
+		return newTracedError("root cause", nil)
+	}
+	return newTracedError("wrapped", wrapDeep(depth-1))
+}
+
+func TestChainStackCollapsesDuplicateFrames(t *testing.T) {
+	const depth = 5
+	err := wrapDeep(depth)
+
+	stack := chainStack(err, false)
+	if n := strings.Count(stack, "TestChainStackCollapsesDuplicateFrames"); n != 1 {
+		t.Errorf("test function printed %d times, want once:\n%s", n, stack)
+	}
+	if !strings.Contains(stack, "wrapped at:") {
+		t.Errorf("wrapping errors' own frames were dropped:\n%s", stack)
+	}
+	if !strings.Contains(stack, "frames elided") {
+		t.Errorf("no elided frames noted:\n%s", stack)
+	}
+
+	full := chainStack(err, true)
+	if n := strings.Count(full, "TestChainStackCollapsesDuplicateFrames"); n != depth+1 {
+		t.Errorf("full stacks printed the test function %d times, want %d", n, depth+1)
+	}
+	if n := strings.Count(full, "wrapped at:"); n != depth {
+		t.Errorf("full stacks have %d wrapped at: sections, want %d", n, depth)
+	}
+	if strings.Contains(full, "frames elided") {
+		t.Error("full stacks elided frames")
+	}
+}
+
+func TestErrorStackUsesFirstErrorField(t *testing.T) {
+	err := wrapDeep(1)
+	fields := []Field{{Key: "user", Value: "ann"}, {Key: "err", Value: err}}
+	if got, want := errorStack(fields, false), chainStack(err, false); got != want || got == "" {
+		t.Errorf("errorStack = %q, want %q", got, want)
+	}
+	if got := errorStack([]Field{{Key: "user", Value: "ann"}}, false); got != "" {
+		t.Errorf("errorStack without an error = %q, want empty", got)
+	}
+}
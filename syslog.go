@@ -0,0 +1,99 @@
+//go:build !windows && !plan9
+
+package simplelog
+
+import (
+	"errors"
+	"log/syslog"
+	"sync"
+)
+
+// SyslogSink is a Sink that writes entries to a syslog daemon, mapping each
+// level to a syslog severity. Messages carry the caller, message and fields;
+// the daemon records the time, host and tag
+type SyslogSink struct {
+	mu        sync.Mutex
+	w         *syslog.Writer
+	formatter Formatter
+	maxSize   int
+}
+
+// NewSyslogSink connects to the syslog daemon at raddr over network ("udp" or
+// "tcp"), or to the local daemon through /dev/log if network is empty. tag
+// identifies the program in each message
+func NewSyslogSink(network, raddr, tag string) (*SyslogSink, error) {
+	w, err := syslog.Dial(network, raddr, syslog.LOG_USER|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogSink{w: w, formatter: messageFormatter{}, maxSize: 8192}, nil
+}
+
+// WithSyslog sends entries to syslog as well, see NewSyslogSink. New returns
+// an error if the daemon can't be reached
+func WithSyslog(network, raddr, tag string) Option {
+	return func(c *config) {
+		c.sinks = append(c.sinks, func() (Sink, error) {
+			return NewSyslogSink(network, raddr, tag)
+		})
+	}
+}
+
+// SetFormatter sets the formatter used for message text
+func (s *SyslogSink) SetFormatter(f Formatter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.formatter = f
+}
+
+// SetMaxMessageSize sets the largest message sent, 8192 bytes by default.
+// Longer messages are truncated; keep it under the datagram size for UDP
+func (s *SyslogSink) SetMaxMessageSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.maxSize = size
+}
+
+// WriteEntry sends entry at the syslog severity matching its level
+func (s *SyslogSink) WriteEntry(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	if s.maxSize > 0 && len(b) > s.maxSize {
+		var ok bool
+		if _, b, ok = fitEntry(s.formatter, entry, b, s.maxSize); !ok {
+			return errors.New("simplelog: entry too large for syslog")
+		}
+	}
+
+	msg := string(b)
+	switch syslogSeverity(entry.Level) {
+	case 7:
+		return s.w.Debug(msg)
+	case 6:
+		return s.w.Info(msg)
+	case 5:
+		return s.w.Notice(msg)
+	case 4:
+		return s.w.Warning(msg)
+	case 3:
+		return s.w.Err(msg)
+	case 2:
+		return s.w.Crit(msg)
+	case 1:
+		return s.w.Alert(msg)
+	default:
+		return s.w.Emerg(msg)
+	}
+}
+
+// Close closes the connection to the daemon
+func (s *SyslogSink) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,71 @@
+package simplelog
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"sync"
+)
+
+// maxWriterLine is the length at which Writer logs a line that hasn't ended
+const maxWriterLine = 64 * 1024
+
+// lineWriter logs each line written to it as an entry
+type lineWriter struct {
+	l     *Logger
+	level LogLevel
+	// skip is the number of frames between the code logging and Write
+	skip int
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// Writer returns an io.Writer that logs each line written to it at level,
+// without its line ending, e.g. for a subprocess's output. A final line
+// without a newline is held until more is written
+func (l *Logger) Writer(level LogLevel) io.Writer {
+	return &lineWriter{l: l, level: level}
+}
+
+// StdLogger returns a standard library logger whose output is logged at
+// level, e.g. for http.Server.ErrorLog. Entries report the caller of its
+// Print methods
+func (l *Logger) StdLogger(level LogLevel) *log.Logger {
+	return log.New(&lineWriter{l: l, level: level, skip: 2}, "", 0)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.buf = append(w.buf, p...)
+			if len(w.buf) >= maxWriterLine {
+				w.logLine(w.buf)
+				w.buf = w.buf[:0]
+			}
+			break
+		}
+		line := p[:i]
+		if len(w.buf) > 0 {
+			line = append(w.buf, line...)
+			w.buf = w.buf[:0]
+		}
+		w.logLine(line)
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// logLine logs line without a trailing carriage return
+func (w *lineWriter) logLine(line []byte) {
+	if !w.l.enabled(w.level) {
+		return
+	}
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	w.l.output(w.skip+1, w.level, string(line), nil)
+}